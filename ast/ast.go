@@ -67,9 +67,10 @@ func (i *Identifier) String() string {
 }
 
 type LetStatement struct {
-	Token token.Token // token.IDENT
-	Name  *Identifier // left side
-	Value Expression  // right side
+	Token   token.Token // token.IDENT
+	Name    *Identifier // left side
+	Pattern Expression  // left side when destructuring : let [a, b] = list, Name is nil then
+	Value   Expression  // right side
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -78,7 +79,11 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -113,6 +118,18 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// throw <expression>;
+type ThrowStatement struct {
+	Token token.Token // the throw token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	return ts.TokenLiteral() + " " + ts.Value.String() + ";"
+}
+
 // expression statements, only expression line
 type ExpressionStatement struct {
 	Token      token.Token
@@ -146,6 +163,27 @@ func (st *StringLiteral) expressionNode()      {}
 func (st *StringLiteral) TokenLiteral() string { return st.Token.Literal }
 func (st *StringLiteral) String() string       { return st.Token.Literal }
 
+// "Hello ${name}, you have ${len(items)} items"
+// the parts are the literal pieces (*StringLiteral) and the embedded expressions, in order
+type InterpolatedString struct {
+	Token token.Token // the INTERP token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+	for _, part := range is.Parts {
+		if lit, ok := part.(*StringLiteral); ok {
+			out.WriteString(lit.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	return out.String()
+}
+
 type ListLiteral struct {
 	Token  token.Token
 	Values []Expression
@@ -171,7 +209,6 @@ type IndexExpression struct {
 	Token token.Token // first [
 	Left  Expression
 	Index Expression // the right side inside the []
-	Value Expression // the value of the assignment
 }
 
 func (ind *IndexExpression) expressionNode()      {}
@@ -183,10 +220,56 @@ func (ind *IndexExpression) String() string {
 	out.WriteString("[")
 	out.WriteString(ind.Index.String())
 	out.WriteString("])")
-	if ind.Value != nil {
-		out.WriteString("=")
-		out.WriteString(ind.Value.String())
+	return out.String()
+}
+
+// Slices : <expression>[<start>:<end>:<step>], each part is optional (nil if omitted) : xs[1:], xs[:-1], xs[::-1]
+type SliceExpression struct {
+	Token token.Token // first [
+	Left  Expression
+	Start Expression
+	End   Expression
+	Step  Expression
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	part := func(e Expression) string {
+		if e == nil {
+			return ""
+		}
+		return e.String()
+	}
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	out.WriteString(part(se.Start) + ":" + part(se.End))
+	if se.Step != nil {
+		out.WriteString(":" + se.Step.String())
 	}
+	out.WriteString("])")
+	return out.String()
+}
+
+// Member access : <expression>.<identifier>
+// obj.name is the same as obj["name"], it only works on hashmaps
+type MemberExpression struct {
+	Token    token.Token // the .
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
 	return out.String()
 }
 
@@ -194,6 +277,7 @@ func (ind *IndexExpression) String() string {
 type HashLiteral struct {
 	Token token.Token // first {
 	Store map[Expression]Expression
+	Keys  []Expression // the keys in source order, they're evaluated in that order
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -201,8 +285,8 @@ func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range hl.Store {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Keys {
+		pairs = append(pairs, key.String()+":"+hl.Store[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -300,10 +384,43 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// try { <block> } catch (<ident>) { <block> } finally { <block> }
+// at least one of catch or finally is given, Param is nil if the catch doesn't bind the error
+type TryExpression struct {
+	Token   token.Token // the try token
+	Block   *BlockStatement
+	Param   *Identifier
+	Catch   *BlockStatement
+	Finally *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+	if te.Catch != nil {
+		out.WriteString(" catch")
+		if te.Param != nil {
+			out.WriteString("(" + te.Param.String() + ")")
+		}
+		out.WriteString(" ")
+		out.WriteString(te.Catch.String())
+	}
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+	return out.String()
+}
+
 // Function Literals
 type FunctionLiteral struct {
-	Token      token.Token // func keyword
-	Parameters []*Identifier
+	Token      token.Token  // func keyword
+	Name       string       // the name it's bound to with let, used in errors
+	Parameters []Expression // identifiers, patterns or defaults : fn(x, [a, b], {name}, y = 1)
+	Rest       *Identifier  // fn(x, ...rest), nil if there is no rest
 	Body       *BlockStatement
 }
 
@@ -315,6 +432,9 @@ func (fl *FunctionLiteral) String() string {
 	for _, p := range fl.Parameters {
 		params = append(params, p.String())
 	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
@@ -344,10 +464,39 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// ...list, spreads the list into call args or a list literal : f(...args), [0, ...rest]
+type SpreadExpression struct {
+	Token token.Token // ...
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+
+// name: <expression> in call args : greet("bob", greeting: "hey")
+type NamedArgument struct {
+	Token token.Token // :
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
+}
+
+// <target> = <expression>
+// the target (left side) is either an identifier, an index expression or a member expression : x, a[i][j], obj.field.sub
+// compound assignments (x += 1) are the same node with the operator set to +=, -=, *=, /= or %=
 type AssignExpression struct {
-	Token token.Token // =
-	Name  *Identifier // left side
-	Value Expression  // right side
+	Token    token.Token // = or the compound operator
+	Target   Expression  // left side
+	Operator string
+	Value    Expression // right side
 }
 
 func (ae *AssignExpression) expressionNode()      {}
@@ -355,11 +504,161 @@ func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
 func (ae *AssignExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ae.Name.Value)
-	out.WriteString(" = ")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
 
 	if ae.Value != nil {
 		out.WriteString(ae.Value.String())
 	}
 	return out.String()
 }
+
+// x++, ++x, a[i]--
+type UpdateExpression struct {
+	Token    token.Token // ++ or --
+	Operator string
+	Target   Expression
+	Prefix   bool // ++x gives the new value, x++ gives the old one
+}
+
+func (ue *UpdateExpression) expressionNode()      {}
+func (ue *UpdateExpression) TokenLiteral() string { return ue.Token.Literal }
+func (ue *UpdateExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	if ue.Prefix {
+		out.WriteString(ue.Operator)
+		out.WriteString(ue.Target.String())
+	} else {
+		out.WriteString(ue.Target.String())
+		out.WriteString(ue.Operator)
+	}
+	out.WriteString(")")
+
+	return out.String()
+}
+
+/*
+Patterns are the left side of a destructuring let and function params, they're bound against a value instead of evaluated.
+
+	let [first, second = 0, ...rest] = list
+	let {name, age: years} = record
+	fn([x, y], {name}) { ... }
+*/
+
+// [a, b = 1, ...rest]
+type ListPattern struct {
+	Token    token.Token  // [
+	Elements []Expression // identifiers, nested patterns or defaults
+	Rest     *Identifier  // ...rest, nil if there is no rest
+}
+
+func (lp *ListPattern) expressionNode()      {}
+func (lp *ListPattern) TokenLiteral() string { return lp.Token.Literal }
+func (lp *ListPattern) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+	for _, el := range lp.Elements {
+		elements = append(elements, el.String())
+	}
+	if lp.Rest != nil {
+		elements = append(elements, "..."+lp.Rest.String())
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
+
+// key: pattern, name is the short form of name: name
+type HashPatternPair struct {
+	Key   string
+	Value Expression
+}
+
+// {name, age: years, city = "Cairo"}
+type HashPattern struct {
+	Token token.Token // {
+	Pairs []*HashPatternPair
+}
+
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		pairs = append(pairs, pair.Key+": "+pair.Value.String())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// <pattern> = <expression>, the default is used when the value is missing (NULL)
+type DefaultPattern struct {
+	Token   token.Token // =
+	Target  Expression
+	Default Expression
+}
+
+func (dp *DefaultPattern) expressionNode()      {}
+func (dp *DefaultPattern) TokenLiteral() string { return dp.Token.Literal }
+func (dp *DefaultPattern) String() string {
+	return dp.Target.String() + " = " + dp.Default.String()
+}
+
+// match (<expression>) { <pattern> => <expression>, <pattern> if <guard> => { <block> } }
+type MatchExpression struct {
+	Token   token.Token // the match token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression // nil if there's no guard
+	Body    *BlockStatement
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	arms := []string{}
+	for _, arm := range me.Arms {
+		a := arm.Pattern.String()
+		if arm.Guard != nil {
+			a += " if " + arm.Guard.String()
+		}
+		arms = append(arms, a+" => "+arm.Body.String())
+	}
+	out.WriteString("match")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" {")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// a type name in a match pattern : int, string, bool, null, list, hash, fn
+type TypePattern struct {
+	Token token.Token
+	Name  string
+}
+
+func (tp *TypePattern) expressionNode()      {}
+func (tp *TypePattern) TokenLiteral() string { return tp.Token.Literal }
+func (tp *TypePattern) String() string       { return tp.Name }
+
+// the identifiers that are type patterns in match instead of bindings, fn is a keyword so the parser handles it on its own
+var TypePatternNames = map[string]bool{
+	"int":    true,
+	"string": true,
+	"bool":   true,
+	"null":   true,
+	"list":   true,
+	"hash":   true,
+}
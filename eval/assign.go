@@ -0,0 +1,118 @@
+/*
+Assignments : x = 1, a[i][j] = 2, obj.field += 3, x++
+
+The target of an assignment is resolved into a reference first, which evaluates the sub expressions of the target
+(the list and the index in a[i], the hashmap in obj.field) left to right exactly once.
+The reference is then used to read the current value (for x += 1 and x++) and to write the new one,
+so a[next()] += 1 only calls next() once.
+*/
+package eval
+
+import (
+	"strings"
+	"trash/ast"
+	"trash/object"
+)
+
+type reference struct {
+	get func() object.Object
+	set func(object.Object) object.Object
+}
+
+func resolveReference(target ast.Expression, env *object.Env) (*reference, object.Object) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return &reference{
+			get: func() object.Object { return evalIdenterifer(target, env) },
+			set: func(val object.Object) object.Object {
+				env.Set(target.Value, val)
+				return val
+			},
+		}, nil
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isErr(left) {
+			return nil, left
+		}
+		index := Eval(target.Index, env)
+		if isErr(index) {
+			return nil, index
+		}
+		return &reference{
+			get: func() object.Object { return evalIndexExpression(left, index, nil, env.Strict()) },
+			set: func(val object.Object) object.Object { return evalIndexExpression(left, index, val, env.Strict()) },
+		}, nil
+
+	case *ast.MemberExpression:
+		obj := Eval(target.Object, env)
+		if isErr(obj) {
+			return nil, obj
+		}
+		name := target.Property.Value
+		return &reference{
+			get: func() object.Object { return evalMemberExpression(obj, name, nil, env.Strict()) },
+			set: func(val object.Object) object.Object { return evalMemberExpression(obj, name, val, env.Strict()) },
+		}, nil
+
+	default:
+		return nil, newErr("Invalid assignment target: %s", target.String())
+	}
+}
+
+// x = v, x += v : the target is resolved before the value is evaluated
+func evalAssignExpression(node *ast.AssignExpression, env *object.Env) object.Object {
+	ref, err := resolveReference(node.Target, env)
+	if err != nil {
+		return err
+	}
+
+	// x += v is x = x + v, without evaluating the target twice
+	var current object.Object
+	if node.Operator != "=" {
+		current = ref.get()
+		if isErr(current) {
+			return current
+		}
+	}
+
+	val := Eval(node.Value, env)
+	if isErr(val) {
+		return val
+	}
+
+	if current != nil {
+		val = evalInfixExpression(current, strings.TrimSuffix(node.Operator, "="), val)
+		if isErr(val) {
+			return val
+		}
+	}
+	return ref.set(val)
+}
+
+// ++x and --x give the updated value, x++ and x-- give the value before the update
+func evalUpdateExpression(node *ast.UpdateExpression, env *object.Env) object.Object {
+	ref, err := resolveReference(node.Target, env)
+	if err != nil {
+		return err
+	}
+
+	current := ref.get()
+	if isErr(current) {
+		return current
+	}
+	if current.Type() != object.INT_OBJ {
+		return newKindErr(object.TYPE_ERROR, "Unknown operator: %s%s", current.Type(), node.Operator)
+	}
+
+	// ++ -> +, -- -> -
+	updated := evalIntInfixExpression(current, node.Operator[:1], &object.Int{Value: 1})
+	if res := ref.set(updated); isErr(res) {
+		return res
+	}
+
+	if node.Prefix {
+		return updated
+	}
+	return current
+}
@@ -6,38 +6,63 @@ package eval
 import (
 	"fmt"
 	"os"
+	"sort"
 	"trash/object"
+	"unicode/utf8"
 )
 
+func init() {
+	registerBuiltins(builtins)
+}
+
+// the builtins in the other files are added in their own init()
+func registerBuiltins(funcs map[string]*object.Builtin) {
+	for name, builtin := range funcs {
+		builtin.Name = name
+		builtins[name] = builtin
+	}
+}
+
+// the names of all the builtins, sorted (the REPL completes them)
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 var builtins = map[string]*object.Builtin{
 	"len": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newErr(`Builtin "len": wrong number of args. got=%d, expected=1`, len(args))
+				return newKindErr(object.ARG_ERROR, `Builtin "len": wrong number of args. got=%d, expected=1`, len(args))
 			}
 
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Int{Value: int64(len(arg.Value))}
+				// the number of characters, not bytes
+				return &object.Int{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.List:
 				return &object.Int{Value: int64(len(arg.Values))}
 			default:
-				return newErr(`Builtin "len" doesn't take %s args`, arg.Type())
+				return newKindErr(object.TYPE_ERROR, `Builtin "len" doesn't take %s args`, arg.Type())
 			}
 		},
 	},
 	// exit with status code
 	"exit": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			if len(args) > 1 {
-				return newErr(`Builtin "len": wrong number of args. got=%d, expected=1`, len(args))
+				return newKindErr(object.ARG_ERROR, `Builtin "len": wrong number of args. got=%d, expected=1`, len(args))
 			}
 
 			statusCode := 0
 			if len(args) == 1 {
 				statusArg, ok := args[0].(*object.Int)
 				if !ok {
-					return newErr("Builtin 'exit': argument must be an integer")
+					return newKindErr(object.TYPE_ERROR, "Builtin 'exit': argument must be an integer")
 				}
 				statusCode = int(statusArg.Value)
 			}
@@ -46,12 +71,62 @@ var builtins = map[string]*object.Builtin{
 			return NULL
 		},
 	},
+	// error(msg, data) : an error value to throw, data is optional
+	"error": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 2 {
+				return newKindErr(object.ARG_ERROR, `Builtin "error": wrong number of args. got=%d, expected=1 to 2`, len(args))
+			}
+			message, ok := args[0].(*object.String)
+			if !ok {
+				return newKindErr(object.TYPE_ERROR, `Builtin "error": message must be a STRING, got %s`, args[0].Type())
+			}
+			var data object.Object = NULL
+			if len(args) == 2 {
+				data = args[1]
+			}
+			return newErrorHash(message.Value, object.ERROR, &object.List{Values: []object.Object{}}, data)
+		},
+	},
+	// get(coll, key, default) : the lenient lookup, default (or NULL) if the index is out of range or the key is missing, even in strict mode
+	"get": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if len(args) < 2 || len(args) > 3 {
+				return newKindErr(object.ARG_ERROR, `Builtin "get": wrong number of args. got=%d, expected=2 to 3`, len(args))
+			}
+			var def object.Object = NULL
+			if len(args) == 3 {
+				def = args[2]
+			}
+			switch coll := args[0].(type) {
+			case *object.List, *object.String, *object.Hashmap:
+				val := evalIndexExpression(coll, args[1], nil, true)
+				if err, ok := val.(*object.Error); ok {
+					if err.Kind == object.INDEX_ERROR || err.Kind == object.KEY_ERROR {
+						return def
+					}
+				}
+				return val
+			default:
+				return newKindErr(object.TYPE_ERROR, `Builtin "get" doesn't take %s args`, coll.Type())
+			}
+		},
+	},
 	"print": {
-		Func: func(args ...object.Object) object.Object {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
 			for _, arg := range args {
 				fmt.Println(arg.Inspect())
 			}
 			return NULL
 		},
 	},
+	// the value as the REPL shows it : repr(["1", 1]) is `["1", 1]`
+	"repr": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("repr", args, 1, 1); err != nil {
+				return err
+			}
+			return &object.String{Value: object.Repr(args[0])}
+		},
+	},
 }
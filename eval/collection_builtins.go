@@ -0,0 +1,272 @@
+/*
+Higher-order built-in functions, they take a function (user defined or builtin) and call it for each element :-
+
+The function is called with (item) for lists and with (key, value) for hashmaps.
+
+	map([1, 2], fn(x) { x * 2 })                  // [2, 4], hashmaps give a list of the results too
+	filter([1, 2, 3], fn(x) { x % 2 == 1 })       // [1, 3], hashmaps give a hashmap of the pairs that are kept
+	reduce([1, 2, 3], fn(acc, x) { acc + x }, 0)  // 6, fn(acc, key, value) for hashmaps,
+	                                              // without the initial value the first element is used (lists only)
+	each(xs, fn(x) { print(x) })                  // null, only for the side effects
+	any(xs, fn(x) { x > 2 }), all(xs, fn(x) { x > 2 })
+	find(xs, fn(x) { x > 2 })                     // the first matching item (a [key, value] list for hashmaps), null if none
+	group_by(xs, fn(x) { x % 2 })                 // {1: [1, 3], 0: [2]}, the groups of a hashmap are lists of [key, value]
+	flat_map(xs, fn(x) { [x, x] })                // like map but the lists returned are flattened (one level)
+	zip([1, 2], ["a", "b", "c"])                  // [[1, a], [2, b]], stops at the shortest list
+
+None of them change the collection they're given.
+An error raised in the function stops the iteration and is returned, with the builtin in its stack.
+*/
+package eval
+
+import (
+	"trash/object"
+)
+
+func init() {
+	registerBuiltins(collectionBuiltins)
+}
+
+// the args of the callback for each element, taken before any call so the callback can change the collection safely
+func callbackArgs(name string, coll object.Object) ([][]object.Object, *object.Error) {
+	calls := [][]object.Object{}
+	switch coll := coll.(type) {
+	case *object.List:
+		for _, item := range coll.Values {
+			calls = append(calls, []object.Object{item})
+		}
+	case *object.Hashmap:
+		for _, pair := range coll.Pairs() {
+			calls = append(calls, []object.Object{pair.Key, pair.Value})
+		}
+	default:
+		return nil, newKindErr(object.TYPE_ERROR, `Builtin "%s" expected a LIST or a HASH, got %s`, name, coll.Type())
+	}
+	return calls, nil
+}
+
+// the element a callback was called for : the item of a list or a [key, value] list for hashmaps
+func callbackItem(args []object.Object) object.Object {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return &object.List{Values: append([]object.Object{}, args...)}
+}
+
+// the common shape of map, filter, each, ... : check the args, then call fn for each element
+func iterate(ctx *object.Context, name string, args []object.Object, visit func(callArgs []object.Object, res object.Object) bool) *object.Error {
+	if err := checkArgs(name, args, 2, 2); err != nil {
+		return err
+	}
+	calls, err := callbackArgs(name, args[0])
+	if err != nil {
+		return err
+	}
+	for _, callArgs := range calls {
+		res := ctx.Call(args[1], callArgs...)
+		if err, ok := res.(*object.Error); ok {
+			return err
+		}
+		if !visit(callArgs, res) {
+			break
+		}
+	}
+	return nil
+}
+
+var collectionBuiltins = map[string]*object.Builtin{
+	"map": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			mapped := []object.Object{}
+			err := iterate(ctx, "map", args, func(_ []object.Object, res object.Object) bool {
+				mapped = append(mapped, res)
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			return &object.List{Values: mapped}
+		},
+	},
+	"filter": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			kept := []object.Object{}
+			hash := object.NewHashmap()
+			err := iterate(ctx, "filter", args, func(callArgs []object.Object, res object.Object) bool {
+				if !isTruthy(res) {
+					return true
+				}
+				if len(callArgs) == 2 {
+					hash.Set(callArgs[0].(object.Hashable), callArgs[1])
+				} else {
+					kept = append(kept, callArgs[0])
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			if args[0].Type() == object.HASHMAP_OBJ {
+				return hash
+			}
+			return &object.List{Values: kept}
+		},
+	},
+	"reduce": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("reduce", args, 2, 3); err != nil {
+				return err
+			}
+			calls, err := callbackArgs("reduce", args[0])
+			if err != nil {
+				return err
+			}
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if args[0].Type() == object.HASHMAP_OBJ {
+					return newKindErr(object.ARG_ERROR, `Builtin "reduce" needs an initial value for a HASH`)
+				}
+				if len(calls) == 0 {
+					return newKindErr(object.ARG_ERROR, `Builtin "reduce" of an empty LIST needs an initial value`)
+				}
+				acc = calls[0][0]
+				calls = calls[1:]
+			}
+			for _, callArgs := range calls {
+				acc = ctx.Call(args[1], append([]object.Object{acc}, callArgs...)...)
+				if isErr(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+	"each": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			err := iterate(ctx, "each", args, func(_ []object.Object, _ object.Object) bool {
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			return NULL
+		},
+	},
+	"any": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			found := false
+			err := iterate(ctx, "any", args, func(_ []object.Object, res object.Object) bool {
+				found = isTruthy(res)
+				return !found
+			})
+			if err != nil {
+				return err
+			}
+			return mapBool(found)
+		},
+	},
+	"all": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			all := true
+			err := iterate(ctx, "all", args, func(_ []object.Object, res object.Object) bool {
+				all = isTruthy(res)
+				return all
+			})
+			if err != nil {
+				return err
+			}
+			return mapBool(all)
+		},
+	},
+	"find": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			var found object.Object = NULL
+			err := iterate(ctx, "find", args, func(callArgs []object.Object, res object.Object) bool {
+				if isTruthy(res) {
+					found = callbackItem(callArgs)
+					return false
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			return found
+		},
+	},
+	"group_by": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			groups := object.NewHashmap()
+			var groupErr *object.Error
+			err := iterate(ctx, "group_by", args, func(callArgs []object.Object, res object.Object) bool {
+				key, ok := res.(object.Hashable)
+				if !ok {
+					groupErr = newKindErr(object.TYPE_ERROR, "Unusable as hashkey: %s", res.Type())
+					return false
+				}
+				group, ok := groups.Get(key)
+				if !ok {
+					group = &object.List{Values: []object.Object{}}
+					groups.Set(key, group)
+				}
+				list := group.(*object.List)
+				list.Values = append(list.Values, callbackItem(callArgs))
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			if groupErr != nil {
+				return groupErr
+			}
+			return groups
+		},
+	},
+	"flat_map": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			flat := []object.Object{}
+			err := iterate(ctx, "flat_map", args, func(_ []object.Object, res object.Object) bool {
+				if list, ok := res.(*object.List); ok {
+					flat = append(flat, list.Values...)
+				} else {
+					flat = append(flat, res)
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			return &object.List{Values: flat}
+		},
+	},
+	"zip": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgsAtLeast("zip", args, 1); err != nil {
+				return err
+			}
+			lists := []*object.List{}
+			shortest := -1
+			for _, arg := range args {
+				list, err := listArg("zip", arg)
+				if err != nil {
+					return err
+				}
+				lists = append(lists, list)
+				if shortest == -1 || len(list.Values) < shortest {
+					shortest = len(list.Values)
+				}
+			}
+			zipped := []object.Object{}
+			for i := 0; i < shortest; i++ {
+				tuple := []object.Object{}
+				for _, list := range lists {
+					tuple = append(tuple, list.Values[i])
+				}
+				zipped = append(zipped, &object.List{Values: tuple})
+			}
+			return &object.List{Values: zipped}
+		},
+	},
+}
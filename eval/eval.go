@@ -2,6 +2,7 @@ package eval
 
 import (
 	"fmt"
+	"strings"
 	"trash/ast"
 	"trash/object"
 	"trash/token"
@@ -14,6 +15,9 @@ var (
 	FALSE = &object.Bool{Value: false}
 )
 
+// the name of the param that gets the receiver in method calls : obj.method()
+const SELF = "self"
+
 func Eval(n ast.Node, env *object.Env) object.Object {
 	switch node := n.(type) {
 
@@ -31,30 +35,49 @@ func Eval(n ast.Node, env *object.Env) object.Object {
 		}
 		return &object.ReturnValue{Value: returnVal}
 
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Params: params, Body: body, Env: env}
+		return &object.Function{Name: node.Name, Params: params, Rest: node.Rest, Body: body, Env: env}
 
 	case *ast.CallExpression:
+		if member, ok := node.Function.(*ast.MemberExpression); ok {
+			return evalMethodCall(member, node.Arguments, env)
+		}
 		function := Eval(node.Function, env)
 
 		if isErr(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args, named := evalArguments(node.Arguments, env)
 		if len(args) == 1 {
 			if isErr(args[0]) {
 				return args[0]
 			}
 		}
-		return getObjectFunction(function, args)
+		return getObjectFunction(function, args, named)
+
+	case *ast.SpreadExpression:
+		return newErr("Spread (%s) is only allowed in call args and list literals", node.String())
+
+	case *ast.NamedArgument:
+		return newErr("Named arg (%s) is only allowed in call args", node.String())
+
 	case *ast.IntegerLiteral:
 		return &object.Int{Value: node.Value}
 
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+
 	case *ast.ListLiteral:
 		values := evalExpressions(node.Values, env)
 
@@ -78,13 +101,18 @@ func Eval(n ast.Node, env *object.Env) object.Object {
 			return index
 		}
 
-		value := Eval(node.Value, env)
-		if isErr(value) {
-			return value
-		}
-
 		// calcs the whole expression after subsituting the index in the expression
-		return evalIndexExpression(left, index, value)
+		return evalIndexExpression(left, index, nil, env.Strict())
+
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isErr(obj) {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Property.Value, nil, env.Strict())
 
 	case *ast.Boolean:
 		return mapBool(node.Value)
@@ -114,6 +142,9 @@ func Eval(n ast.Node, env *object.Env) object.Object {
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
 	// builtin functions are also Identifiers
 	case *ast.Identifier:
 		return evalIdenterifer(node, env)
@@ -123,22 +154,36 @@ func Eval(n ast.Node, env *object.Env) object.Object {
 		if isErr(val) {
 			return val
 		}
+		if node.Pattern != nil {
+			return bindPattern(node.Pattern, val, env)
+		}
 		env.Set(node.Name.Value, val)
 
-	// TODO: really duplicate code from LetStatement
 	case *ast.AssignExpression:
-		val := Eval(node.Value, env)
-		if isErr(val) {
-			return val
-		}
-		env.Set(node.Name.Value, val)
+		return evalAssignExpression(node, env)
+
+	case *ast.UpdateExpression:
+		return evalUpdateExpression(node, env)
 	}
 	return nil
 }
 
+// ...list expands into the list elements
 func evalExpressions(exps []ast.Expression, env *object.Env) []object.Object {
 	var result []object.Object
 	for _, obj := range exps {
+		if spread, ok := obj.(*ast.SpreadExpression); ok {
+			evaluted := Eval(spread.Value, env)
+			if isErr(evaluted) {
+				return []object.Object{evaluted}
+			}
+			list, ok := evaluted.(*object.List)
+			if !ok {
+				return []object.Object{newKindErr(object.TYPE_ERROR, "Cannot spread %s, expected a LIST", evaluted.Type())}
+			}
+			result = append(result, list.Values...)
+			continue
+		}
 		evaluted := Eval(obj, env)
 		if isErr(evaluted) {
 			return []object.Object{evaluted}
@@ -148,40 +193,122 @@ func evalExpressions(exps []ast.Expression, env *object.Env) []object.Object {
 	return result
 }
 
-func evalIndexExpression(left, index, value object.Object) object.Object {
+// name: value in call args
+type namedArg struct {
+	name  string
+	value object.Object
+}
+
+// the positional args (with the spreads expanded) and the named ones, the parser makes sure the named args are the last ones
+func evalArguments(exps []ast.Expression, env *object.Env) ([]object.Object, []namedArg) {
+	var named []namedArg
+	for i, exp := range exps {
+		if _, ok := exp.(*ast.NamedArgument); !ok {
+			continue
+		}
+		args := evalExpressions(exps[:i], env)
+		if len(args) == 1 && isErr(args[0]) {
+			return args, nil
+		}
+		for _, exp := range exps[i:] {
+			na := exp.(*ast.NamedArgument)
+			val := Eval(na.Value, env)
+			if isErr(val) {
+				return []object.Object{val}, nil
+			}
+			named = append(named, namedArg{name: na.Name.Value, value: val})
+		}
+		return args, named
+	}
+	return evalExpressions(exps, env), nil
+}
+
+// in strict mode, out of range indices and missing keys are errors, otherwise they're NULL
+func evalIndexExpression(left, index, value object.Object, strict bool) object.Object {
 	switch {
-	case left.Type() == object.LIST_OBJ && index.Type() == object.INT_OBJ:
-		return evalListIndexExpression(left, index, value)
+	case (left.Type() == object.LIST_OBJ || left.Type() == object.STR_OBJ) && index.Type() != object.INT_OBJ:
+		return newKindErr(object.TYPE_ERROR, "%s index must be INT, got %s", left.Type(), index.Type())
+	case left.Type() == object.LIST_OBJ:
+		return evalListIndexExpression(left, index, value, strict)
 	case left.Type() == object.HASHMAP_OBJ:
-		return evalHashIndexExpression(left, index, value)
+		return evalHashIndexExpression(left, index, value, strict)
+	case left.Type() == object.STR_OBJ:
+		if value != nil {
+			return newKindErr(object.TYPE_ERROR, "Index assignment not supported: %s, strings are immutable", left.Type())
+		}
+		return evalStringIndexExpression(left, index, strict)
 	default:
-		return newErr("Index operator not supported: %s", left.Type())
+		return newKindErr(object.TYPE_ERROR, "Index operator not supported: %s", left.Type())
 	}
 }
 
-func evalHashIndexExpression(hash, index, value object.Object) object.Object {
+func evalHashIndexExpression(hash, index, value object.Object, strict bool) object.Object {
 	hashObject := hash.(*object.Hashmap)
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newErr("Unusable as hashkey: %s", index.Type())
+		return newKindErr(object.TYPE_ERROR, "Unusable as hashkey: %s", index.Type())
 	}
 
 	if value != nil {
-		hashObject.Store[key.HashKey()] = object.HashPair{Key: index, Value: value}
+		hashObject.Set(key, value)
 		return value
 	}
-	pair, ok := hashObject.Store[key.HashKey()]
+	val, ok := hashObject.Get(key)
 	if !ok {
+		if strict {
+			return newKindErr(object.KEY_ERROR, "Key not found: %s", object.Repr(index))
+		}
 		return NULL
 	}
-	return pair.Value
+	return val
+}
+
+// obj.name is sugar for obj["name"], so the property is always a string key
+func evalMemberExpression(obj object.Object, name string, value object.Object, strict bool) object.Object {
+	if obj.Type() != object.HASHMAP_OBJ {
+		return newKindErr(object.TYPE_ERROR, "Member access not supported: %s", obj.Type())
+	}
+	return evalHashIndexExpression(obj, &object.String{Value: name}, value, strict)
+}
+
+// obj.method(args), if the method's first param is called self, the receiver (obj) is passed as the first arg.
+func evalMethodCall(member *ast.MemberExpression, arguments []ast.Expression, env *object.Env) object.Object {
+	receiver := Eval(member.Object, env)
+	if isErr(receiver) {
+		return receiver
+	}
+
+	function := evalMemberExpression(receiver, member.Property.Value, nil, env.Strict())
+	if isErr(function) {
+		return function
+	}
+
+	args, named := evalArguments(arguments, env)
+	if len(args) == 1 && isErr(args[0]) {
+		return args[0]
+	}
+
+	if fn, ok := function.(*object.Function); ok && len(fn.Params) > 0 {
+		if first, ok := fn.Params[0].(*ast.Identifier); ok && first.Value == SELF {
+			args = append([]object.Object{receiver}, args...)
+		}
+	}
+	return getObjectFunction(function, args, named)
 }
-func evalListIndexExpression(list, index, value object.Object) object.Object {
+
+func evalListIndexExpression(list, index, value object.Object, strict bool) object.Object {
 	listObj := list.(*object.List)
 	idx := index.(*object.Int).Value
-	maxLen := int64(len(listObj.Values) - 1)
+	length := int64(len(listObj.Values))
 
-	if idx < 0 || idx > maxLen {
+	// negative indices count from the end : xs[-1] is the last element
+	if idx < 0 {
+		idx += length
+	}
+	if idx < 0 || idx >= length {
+		if strict {
+			return indexErr(index, length)
+		}
 		return NULL
 	}
 	if value != nil {
@@ -190,39 +317,151 @@ func evalListIndexExpression(list, index, value object.Object) object.Object {
 
 	return listObj.Values[idx]
 }
-func getObjectFunction(function object.Object, args []object.Object) object.Object {
+func getObjectFunction(function object.Object, args []object.Object, named []namedArg) object.Object {
 
 	switch fn := function.(type) {
 	case *object.Function:
-		// mismatching args with given
-		// TODO: add optional args
-		if len(args) != len(fn.Params) {
-			return newErr("Error: missing args to the function: %s", function.Inspect())
+		expandedEnv, err := expandFunctionEnv(fn, args, named)
+		if err != nil {
+			return err
 		}
-		expandedEnv := expandFunctionEnv(fn, args)
+		applyPragmas(fn.Body.Statements, expandedEnv)
 		evaluated := Eval(fn.Body, expandedEnv)
 		if returnVal, ok := evaluated.(*object.ReturnValue); ok {
 			return returnVal.Value
 		}
+		// record the function the error passed through, shown in the stack of a caught error
+		if err, ok := evaluated.(*object.Error); ok {
+			err.Stack = append(err.Stack, fn.Signature())
+		}
 		return evaluated
 
 	case *object.Builtin:
-		// just call the function
-		return fn.Func(args...)
+		if len(named) != 0 {
+			return newKindErr(object.ARG_ERROR, "Builtin functions don't take named args, got %s", named[0].name)
+		}
+		// callbacks of the builtin are called like any other function, an error raised in them also records the builtin in its stack
+		calledBack := false
+		ctx := &object.Context{
+			Call: func(callback object.Object, args ...object.Object) object.Object {
+				res := getObjectFunction(callback, args, nil)
+				if isErr(res) {
+					calledBack = true
+				}
+				return res
+			},
+		}
+		res := fn.Func(ctx, args...)
+		if err, ok := res.(*object.Error); ok && calledBack {
+			err.Stack = append(err.Stack, fn.Name)
+		}
+		return res
+	default:
+		return newKindErr(object.TYPE_ERROR, "%s isn't a function (user defined or builtin).", fn.Inspect())
+	}
+}
+
+// the name a param can be given with in a named arg : x and x = 1 have a name, patterns don't
+func paramName(param ast.Expression) string {
+	if dp, ok := param.(*ast.DefaultPattern); ok {
+		param = dp.Target
+	}
+	if ident, ok := param.(*ast.Identifier); ok {
+		return ident.Value
+	}
+	return ""
+}
+
+// the number of params without defaults
+func requiredParams(function *object.Function) int {
+	required := 0
+	for _, param := range function.Params {
+		if _, ok := param.(*ast.DefaultPattern); !ok {
+			required++
+		}
+	}
+	return required
+}
+
+func arityErr(function *object.Function, given int) *object.Error {
+	required := requiredParams(function)
+	var expected string
+	switch {
+	case function.Rest != nil:
+		expected = fmt.Sprintf("at least %d", required)
+	case required != len(function.Params):
+		expected = fmt.Sprintf("%d to %d", required, len(function.Params))
 	default:
-		return newErr("%s isn't a function (user defined or builtin).", fn.Inspect())
+		expected = fmt.Sprintf("%d", required)
 	}
+	return newKindErr(object.ARG_ERROR, "Wrong number of args to %s: expected %s, given %d", function.Signature(), expected, given)
 }
 
-func expandFunctionEnv(function *object.Function, args []object.Object) *object.Env {
+// binds the args to the params : positional args first, then named ones, then the defaults, what's left goes into the rest param
+func expandFunctionEnv(function *object.Function, args []object.Object, named []namedArg) (*object.Env, object.Object) {
+	if len(args) > len(function.Params) && function.Rest == nil {
+		return nil, arityErr(function, len(args)+len(named))
+	}
+
+	names := map[string]bool{}
+	for _, param := range function.Params {
+		names[paramName(param)] = true
+	}
+	namedArgs := map[string]object.Object{}
+	for _, arg := range named {
+		if arg.name == "" || !names[arg.name] {
+			return nil, newKindErr(object.ARG_ERROR, "Unknown named arg %s to %s", arg.name, function.Signature())
+		}
+		namedArgs[arg.name] = arg.value
+	}
+
 	env := object.NewEnclosedEnv(function.Env)
 	for i, param := range function.Params {
-		env.Set(param.Value, args[i])
+		name := paramName(param)
+		val, isNamed := namedArgs[name]
+
+		switch {
+		case i < len(args):
+			if isNamed {
+				return nil, newKindErr(object.ARG_ERROR, "Arg %s to %s is given twice", name, function.Signature())
+			}
+			val = args[i]
+		case isNamed:
+		default:
+			if _, ok := param.(*ast.DefaultPattern); !ok {
+				if len(args)+len(named) < requiredParams(function) {
+					return nil, arityErr(function, len(args)+len(named))
+				}
+				return nil, newKindErr(object.ARG_ERROR, "Missing arg %s to %s", param.String(), function.Signature())
+			}
+			// not given, bindPattern evaluates the default
+			val = nil
+		}
+
+		if err := bindPattern(param, val, env); err != nil {
+			return nil, err
+		}
+	}
+
+	if function.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(function.Params) {
+			rest = append(rest, args[len(function.Params):]...)
+		}
+		env.Set(function.Rest.Value, &object.List{Values: rest})
 	}
-	return env
+	return env, nil
+}
+func indexErr(index object.Object, length int64) *object.Error {
+	return newKindErr(object.INDEX_ERROR, "Index out of range: %s, length is %d", index.Inspect(), length)
 }
+
 func newErr(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	return newKindErr(object.ERROR, format, a...)
+}
+
+func newKindErr(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind}
 }
 
 func evalIdenterifer(node *ast.Identifier, env *object.Env) object.Object {
@@ -233,7 +472,7 @@ func evalIdenterifer(node *ast.Identifier, env *object.Env) object.Object {
 	if val, ok := builtins[node.Value]; ok {
 		return val
 	}
-	return newErr("Identifier not found: %s", node.Value)
+	return newKindErr(object.NAME_ERROR, "Identifier not found: %s", node.Value)
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Env) object.Object {
@@ -264,6 +503,10 @@ func isTruthy(obj object.Object) bool {
 func evalInfixExpression(left object.Object, op string, right object.Object) object.Object {
 	// integars
 	switch {
+	case op == "is":
+		return mapBool(object.Identical(left, right))
+	case op == "in":
+		return evalInExpression(left, right)
 	case left.Type() == object.INT_OBJ && right.Type() == object.INT_OBJ:
 		return evalIntInfixExpression(left, op, right)
 
@@ -271,14 +514,22 @@ func evalInfixExpression(left object.Object, op string, right object.Object) obj
 	case left.Type() == object.STR_OBJ && right.Type() == object.STR_OBJ:
 		return evalStringConcat(left, op, right)
 
+	case op == token.PLUS && left.Type() == object.LIST_OBJ && right.Type() == object.LIST_OBJ:
+		return evalListConcat(left, right)
+	case op == token.MUL && isSequence(left) && right.Type() == object.INT_OBJ:
+		return evalRepeat(left, right)
+	case op == token.MUL && left.Type() == object.INT_OBJ && isSequence(right):
+		return evalRepeat(right, left)
+
+	// deep equality, see object/equal.go
 	case op == "==":
-		return mapBool(left == right)
+		return mapBool(object.Equal(left, right))
 	case op == "!=":
-		return mapBool(left != right)
+		return mapBool(!object.Equal(left, right))
 	case left.Type() != right.Type():
-		return newErr("Type mismatch: %s %s %s", left.Type(), op, right.Type())
+		return newKindErr(object.TYPE_ERROR, "Type mismatch: %s %s %s", left.Type(), op, right.Type())
 	default:
-		return newErr("Unknown operator: %s %s %s", left.Type(), op, right.Type())
+		return newKindErr(object.TYPE_ERROR, "Unknown operator: %s %s %s", left.Type(), op, right.Type())
 	}
 }
 
@@ -286,32 +537,50 @@ func evalStringConcat(left object.Object, op string, right object.Object) object
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 
+	switch op {
+	case token.EQUAL:
+		return mapBool(leftVal == rightVal)
+	case token.NOT_EQUAL:
+		return mapBool(leftVal != rightVal)
+	}
 	if op != token.PLUS {
-		return newErr("Unknown concat operator: '%s', use %s", op, token.COLON)
+		return newKindErr(object.TYPE_ERROR, "Unknown concat operator: '%s', use %s", op, token.COLON)
 	}
 
 	return &object.String{Value: leftVal + rightVal}
 }
 
+// the values are put in the string with their display form, strings without quotes
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Env) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		val := Eval(part, env)
+		if isErr(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
 func evalHashLiteral(node *ast.HashLiteral, env *object.Env) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
-	for keyNode, valueNode := range node.Store {
+	hash := object.NewHashmap()
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isErr(key) {
 			return key
 		}
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return newErr("Unusable as hashkey: %s", key.Type())
+			return newKindErr(object.TYPE_ERROR, "Unusable as hashkey: %s", key.Type())
 		}
-		value := Eval(valueNode, env)
+		value := Eval(node.Store[keyNode], env)
 		if isErr(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey, value)
 	}
-	return &object.Hashmap{Store: pairs}
+	return hash
 }
 
 func evalIntInfixExpression(left object.Object, op string, right object.Object) object.Object {
@@ -329,7 +598,31 @@ func evalIntInfixExpression(left object.Object, op string, right object.Object)
 		return &object.Int{Value: leftVal * rightVal}
 	// TODO: return float values after impl float vars
 	case "/":
+		if rightVal == 0 {
+			return newKindErr(object.ZERO_DIV_ERROR, "Division by zero: %d / 0", leftVal)
+		}
 		return &object.Int{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newKindErr(object.ZERO_DIV_ERROR, "Division by zero: %d %% 0", leftVal)
+		}
+		return &object.Int{Value: leftVal % rightVal}
+
+	// bitwise expressions, shifting by 64 or more gives 0 (or -1 when shifting a negative number right)
+	case "&":
+		return &object.Int{Value: leftVal & rightVal}
+	case "|":
+		return &object.Int{Value: leftVal | rightVal}
+	case "^":
+		return &object.Int{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newKindErr(object.VALUE_ERROR, "Negative shift count: %d %s %d", leftVal, op, rightVal)
+		}
+		if op == "<<" {
+			return &object.Int{Value: leftVal << uint64(rightVal)}
+		}
+		return &object.Int{Value: leftVal >> uint64(rightVal)}
 
 	// boolean expressions
 	case "<":
@@ -350,15 +643,20 @@ func evalPrefixExpression(op string, right object.Object) object.Object {
 		return evalBangOpExpression(right)
 	case "-":
 		return evalMinusOpExpression(right)
+	case "~":
+		if right.Type() != object.INT_OBJ {
+			return newKindErr(object.TYPE_ERROR, "Unknown operator: ~%s", right.Type())
+		}
+		return &object.Int{Value: ^right.(*object.Int).Value}
 	default:
-		return newErr("Unknown Error: %s%s", op, right)
+		return newKindErr(object.TYPE_ERROR, "Unknown Error: %s%s", op, right)
 	}
 }
 
 func evalMinusOpExpression(right object.Object) object.Object {
 	// check if the expression is bool
 	if right.Type() != object.INT_OBJ {
-		return newErr("Unknown operator: -%s", right.Type())
+		return newKindErr(object.TYPE_ERROR, "Unknown operator: -%s", right.Type())
 	}
 	val := right.(*object.Int).Value
 	return &object.Int{Value: -val}
@@ -380,6 +678,8 @@ func evalBangOpExpression(right object.Object) object.Object {
 func evalProgram(prog *ast.Program, env *object.Env) object.Object {
 	var res object.Object
 
+	applyPragmas(prog.Statements, env)
+
 	for _, stmt := range prog.Statements {
 		// The return value of the outer call to Eval is the return value of the last call
 		res = Eval(stmt, env)
@@ -393,6 +693,20 @@ func evalProgram(prog *ast.Program, env *object.Env) object.Object {
 	return res
 }
 
+// "use strict" as the first statement of a program or a function body turns on strict mode for it (and the functions defined in it)
+const STRICT_PRAGMA = "use strict"
+
+func applyPragmas(statements []ast.Statement, env *object.Env) {
+	if len(statements) == 0 {
+		return
+	}
+	if stmt, ok := statements[0].(*ast.ExpressionStatement); ok {
+		if str, ok := stmt.Expression.(*ast.StringLiteral); ok && str.Value == STRICT_PRAGMA {
+			env.SetStrict(true)
+		}
+	}
+}
+
 func evalBlockStatement(block *ast.BlockStatement, env *object.Env) object.Object {
 	var res object.Object
 
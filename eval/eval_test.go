@@ -1,7 +1,9 @@
 package eval
 
 import (
+	"strings"
 	"testing"
+	"trash/ast"
 	"trash/lexer"
 	"trash/object"
 	"trash/parser"
@@ -27,6 +29,22 @@ func TestEvalIntExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 9 % 4 * 3", 5},
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"~5", -6},
+		{"~-1", 0},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"1 << 63", -9223372036854775808},
+		{"1 << 64", 0},
+		{"5 >> 100", 0},
+		{"-5 >> 100", -1},
+		{"(0x1234 >> 8) & 0xFF", 0x12},
+		{"1 | 2 ^ 3 & 4 << 1", 3},
 	}
 
 	for _, tt := range tests {
@@ -136,6 +154,22 @@ func TestErrorHandling(t *testing.T) {
 			"-true",
 			"Unknown operator: -BOOL",
 		},
+		{
+			"~true",
+			"Unknown operator: ~BOOL",
+		},
+		{
+			"1 << -1",
+			"Negative shift count: 1 << -1",
+		},
+		{
+			"1 & true",
+			"Type mismatch: INT & BOOL",
+		},
+		{
+			`"a" | "b"`,
+			"Unknown concat operator: '|', use :",
+		},
 		{
 			"true + false;",
 			"Unknown operator: BOOL + BOOL",
@@ -165,6 +199,26 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "Monkey"}[fn(x) { x }];`,
 			"Unusable as hashkey: FUNCTION",
 		},
+		{
+			"10 / 0",
+			"Division by zero: 10 / 0",
+		},
+		{
+			"10 % 0",
+			"Division by zero: 10 % 0",
+		},
+		{
+			"y += 1",
+			"Identifier not found: y",
+		},
+		{
+			`let s = "a"; s++`,
+			"Unknown operator: STRING++",
+		},
+		{
+			`let s = "a"; s -= "b"`,
+			"Unknown concat operator: '-', use :",
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -234,17 +288,18 @@ func TestLetStatement(t *testing.T) {
 		{"let x = 6; x;", 6},
 		{"let x = -9; let y = x; y", -9},
 		{"let x = -6; let y = x + 6; y", 0},
-		// {"let x = 5 + 5 + 5 + 5 - 10; x = x + 10; x", 20}, // doesn't work !!
-		// {"let x = 2 * 2 * 2 * 2 * 2; let a = x; a = a * 2; a", 64}, // doesn't work !!
+		{"let x = 5 + 5 + 5 + 5 - 10; x = x + 10; x", 20},
+		{"let x = 2 * 2 * 2 * 2 * 2; let a = x; a = a * 2; a", 64},
 		{"let x = -50 + 100 + -50; x;", 0},
 		{"let x = 5 * 2 + 10; x;", 20},
-		// {"let x = 5 + 2 * 10; x = x - 5", 20}, // doesn't work !!
+		{"let x = 5 + 2 * 10; x = x - 5", 20},
 		{"let x = 20 + 2 * -10; x;", 0},
 		{"let x = 50 / 2 * 2 + 10; x;", 60},
 		{"let x = 2 * (5 + 10); x;", 30},
 		{"let x = 3 * 3 * 3 + 10; x;", 37},
 		{"let x = 3 * (3 * 3) + 10; x;", 37},
 		{"let x = (5 + 10 * 2 + 15 / 3) * 2 + -10; x;", 50},
+		{"let café = 3; let λ = fn(π) { π * café }; λ(2)", 6},
 	}
 
 	for _, tt := range tests {
@@ -317,6 +372,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len(12)`, `Builtin "len" doesn't take INT args`},
 		{`len("1", "2")`, `Builtin "len": wrong number of args. got=2, expected=1`},
 		{`len()`, `Builtin "len": wrong number of args. got=0, expected=1`},
+		{`repr()`, `Builtin "repr": wrong number of args. got=0, expected=1`},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -379,6 +435,14 @@ func TestEvalIndexList(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -469,3 +533,935 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestHashMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{
+			`{"foo": 5}.foo`,
+			5,
+		},
+		{
+			`{"foo": 5}.bar`,
+			nil,
+		},
+		{
+			`let p = {"age": 1}; p.age = p.age + 1; p["age"]`,
+			2,
+		},
+		{
+			`let p = {}; p.inner = {"x": 3}; p.inner.x`,
+			3,
+		},
+		{
+			`let m = {"add": fn(a, b) { a + b }}; m.add(2, 3)`,
+			5,
+		},
+		{
+			`let c = {"n": 10, "get": fn(self, d) { self.n + d }}; c.get(5)`,
+			15,
+		},
+		{
+			`5.foo`,
+			"Member access not supported: INT",
+		},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 1; let y = 1; x = y = 5; x + y", 10},
+		{"let a = [[1, 2], [3, 4]]; a[1][0] = 30; a[1][0]", 30},
+		{`let m = {"k": {"inner": 1}}; m["k"]["inner"] = 7; m.k.inner`, 7},
+		{`let obj = {"field": {"sub": 1}}; obj.field.sub = 9; obj["field"]["sub"]`, 9},
+		{`let obj = {"list": [1, 2]}; obj.list[1] = 20; obj.list[1]`, 20},
+		// a function binds the name in its own env, the outer one isn't changed
+		{"let n = 1; let f = fn() { n = 5; n }; f() * 10 + n", 51},
+		// the target is evaluated once and before the value
+		{`let c = {"n": 0}; let next = fn() { c.n = c.n + 1; c.n }; let a = [0, 0, 0]; a[next()] = c.n * 10; a[1] + c.n`, 11},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCompoundAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x += 2; x", 3},
+		{"let x = 10; x -= 2; x", 8},
+		{"let x = 3; x *= 4; x", 12},
+		{"let x = 12; x /= 5; x", 2},
+		{"let x = 12; x %= 5; x", 2},
+		{"let x = 1; x += x += 1", 3},
+		{"let a = [1, 2]; a[1] *= 10; a[1]", 20},
+		{`let o = {"n": 5}; o.n -= 1; o["n"]`, 4},
+		{`let c = {"n": 0}; let add = fn(d) { c.n += d }; add(2); add(3); c.n`, 5},
+		// the index is only evaluated once
+		{`let c = {"n": 0}; let next = fn() { c.n++; c.n }; let a = [0, 10, 20]; a[next()] += 5; a[1] * 10 + c.n`, 151},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestUpdateExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x++", 1},
+		{"let x = 1; x++; x", 2},
+		{"let x = 1; ++x", 2},
+		{"let x = 1; x--", 1},
+		{"let x = 1; --x", 0},
+		{"let x = 5; let y = x++ + x; y", 11},
+		{"let a = [1, 2]; a[0]++; a[0]", 2},
+		{`let o = {"n": 5}; --o.n`, 4},
+		{`let c = {"n": 0}; let next = fn() { c.n++; c.n }; let a = [0, 10, 20]; a[next()]++; a[1] + c.n`, 12},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestLetPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [a, b] = [1, 2, 3]; b", 2},
+		{"let [a, b] = [1]; b", nil},
+		{"let [a, b = 5] = [1]; b", 5},
+		{"let [a, b = a * 2] = [4]; b", 8},
+		{"let [a = 5] = [{}.x]; a", nil},
+		{"let [a, ...rest] = [1, 2, 3]; len(rest)", 2},
+		{"let [a, ...rest] = [1, 2, 3]; rest[1]", 3},
+		{"let [a, b, ...rest] = [1]; len(rest)", 0},
+		{"let [[a, b], [c]] = [[1, 2], [3]]; a + b + c", 6},
+		{"let divmod = fn(a, b) { [a / b, a % b] }; let [q, r] = divmod(17, 5); q * 10 + r", 32},
+		{`let {name, age: years} = {"name": "trash", "age": 2}; years`, 2},
+		{`let {name} = {"name": 7}; name`, 7},
+		{`let {missing} = {}; missing`, nil},
+		{`let {missing = 3} = {}; missing`, 3},
+		{`let {given = 3} = {"given": {}.x}; given`, nil},
+		{`let {"first name": first} = {"first name": 1}; first`, 1},
+		{`let {point: [x, y]} = {"point": [3, 4]}; x * y`, 12},
+		{`let {items: [first, ...others]} = {"items": [1, 2, 3]}; first + len(others)`, 3},
+		{"let [a, b] = 5", "Cannot destructure INT as a list: [a, b]"},
+		{`let {a} = [1]`, "Cannot destructure LIST as a hashmap: {a: a}"},
+		{`let [{a}] = [1]`, "Cannot destructure INT as a hashmap: {a: a}"},
+		{"let [a = xyz] = []", "Identifier not found: xyz"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestFunctionPatternParams(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let add = fn([a, b]) { a + b }; add([2, 3])", 5},
+		{`let area = fn({w, h}) { w * h }; area({"w": 2, "h": 4})`, 8},
+		{"let f = fn(x, [y, ...ys]) { x + y + len(ys) }; f(1, [2, 3, 4])", 5},
+		{`let f = fn({n = 10}) { n }; f({})`, 10},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionParams(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let add = fn(a, b = 10) { a + b }; add(1)", 11},
+		{"let add = fn(a, b = 10) { a + b }; add(1, 2)", 3},
+		{"let add = fn(a, b = a * 2) { a + b }; add(3)", 9},
+		// a null arg is given, it doesn't take the default
+		{"let f = fn(a = 1) { match (a) { null => 0, _ => a } }; f({}.x)", 0},
+		{"let f = fn(a = 1) { match (a) { null => 0, _ => a } }; f(a: {}.x)", 0},
+		{"let count = fn(first, ...rest) { len(rest) }; count(1, 2, 3)", 2},
+		{"let count = fn(first, ...rest) { len(rest) }; count(1)", 0},
+		{"let add = fn(a, b, c) { a + b + c }; add(...[1, 2, 3])", 6},
+		{"let add = fn(a, b, c) { a + b + c }; add(1, ...[2], ...[3])", 6},
+		{"let last = fn(...all) { all[len(all) - 1] }; last(...[1, 2], 3, ...[4])", 4},
+		{"len([0, ...[1, 2], 3])", 4},
+		{"let sub = fn(a, b) { a - b }; sub(b: 1, a: 10)", 9},
+		{"let sub = fn(a, b = 5) { a - b }; sub(10, b: 1)", 9},
+		{"let f = fn(a, b = 1, c = 2) { a * 100 + b * 10 + c }; f(3, c: 5)", 315},
+		{
+			"let add = fn(a, b) { a + b }; add(1)",
+			"Wrong number of args to add(a, b): expected 2, given 1",
+		},
+		{
+			"let add = fn(a, b = 10) { a + b }; add(1, 2, 3)",
+			"Wrong number of args to add(a, b = 10): expected 1 to 2, given 3",
+		},
+		{
+			"let f = fn(a, ...rest) { a }; f()",
+			"Wrong number of args to f(a, ...rest): expected at least 1, given 0",
+		},
+		{
+			"fn(x) { x }()",
+			"Wrong number of args to fn(x): expected 1, given 0",
+		},
+		{
+			"let add = fn(a, b) { a + b }; add(1, c: 2)",
+			"Unknown named arg c to add(a, b)",
+		},
+		{
+			"let add = fn(a, b) { a + b }; add(1, a: 2)",
+			"Arg a to add(a, b) is given twice",
+		},
+		{
+			"let f = fn(a, b = 1, c) { a }; f(a: 1, b: 2)",
+			"Missing arg c to f(a, b = 1, c)",
+		},
+		{
+			"let f = fn(a) { a }; f(...5)",
+			"Cannot spread INT, expected a LIST",
+		},
+		{
+			`len(x: "a")`,
+			"Builtin functions don't take named args, got x",
+		},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"match (1) { 1 => 10, _ => 20 }", 10},
+		{"match (2) { 1 => 10, _ => 20 }", 20},
+		{"match (-1) { -1 => 10, _ => 20 }", 10},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{"match (false) { true => 1, false => 2 }", 2},
+		{"match (5) { n => n * 2 }", 10},
+		{"match (5) { n if n > 10 => 1, n if n > 1 => 2, _ => 3 }", 2},
+		{"match ([]) { [] => 1, _ => 2 }", 1},
+		{"match ([1, 2]) { [a] => a, [a, b] => a + b, _ => 0 }", 3},
+		{"match ([1, 2, 3]) { [a, b] => 0, [a, ...rest] => a + len(rest) }", 3},
+		{"match ([1, [2, 3]]) { [a, [b, c]] => a + b + c }", 6},
+		{"match ([1, 2]) { [2, x] => 0, [1, x] => x }", 2},
+		{`match ({"kind": "sq", "w": 3}) { {"kind": "circle", r} => r, {"kind": "sq", w} => w * w }`, 9},
+		{`match ({"w": 3}) { {w, h} => 0, {w} => w }`, 3},
+		{`match ("x") { int => 1, string => 2 }`, 2},
+		{`match ([]) { hash => 1, list => 2 }`, 2},
+		{`match (len) { fn => 1, _ => 2 }`, 1},
+		{`match (fn() {}) { fn => 1, _ => 2 }`, 1},
+		{`match (if (false) { 1 }) { null => 1, _ => 2 }`, 1},
+		{"match (3) { n => { let m = n * 2; m + 1 } }", 7},
+		// the bindings don't leak out of the arm
+		{"let n = 1; match (5) { n => n }; n", 1},
+		{"let f = fn(x) { match (x) { 0 => { return 100 }, _ => 1 }; 200 }; f(0)", 100},
+		{"match (5) { 1 => 2 }", "No match arm for value: 5"},
+		{`match ("5") { 5 => 2 }`, `No match arm for value: "5"`},
+		{"match (5) { n if m => 2 }", "Identifier not found: m"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+// every type pattern the parser reads matches some object types
+func TestTypePatterns(t *testing.T) {
+	for name := range ast.TypePatternNames {
+		if len(typePatterns[name]) == 0 {
+			t.Errorf("type pattern %q has no object types", name)
+		}
+	}
+	if len(typePatterns["fn"]) == 0 {
+		t.Errorf("type pattern \"fn\" has no object types")
+	}
+}
+
+func TestTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"try { 1 } catch (e) { 2 }", 1},
+		{"try { throw 1; 2 } catch (e) { 3 }", 3},
+		{"try { x } catch { 4 }", 4},
+		{"try { 1 + true } catch (e) { 5 }", 5},
+		{"let f = fn() { throw \"boom\" }; try { f(); 1 } catch (e) { 2 }", 2},
+		{"try { throw [1, 2] } catch (e) { e.data[1] }", 2},
+		{"try { throw error(\"bad\", 7) } catch (e) { e.data }", 7},
+		{"try { 1 } catch (e) { }", 1},
+		{"try { } catch (e) { 1 }", nil},
+		// finally always runs, its value is discarded
+		{"let x = 0; try { 1 } finally { x = 5 }; x", 5},
+		{"let x = 0; try { throw 1 } catch { 2 } finally { x = 6 }; x", 6},
+		{"try { 1 } finally { 2 }", 1},
+		// a return or an error from finally overrides
+		{"let f = fn() { try { return 1 } finally { return 2 } }; f()", 2},
+		{"let f = fn() { try { return 1 } catch { 0 } }; f()", 1},
+		{"try { 1 } finally { throw \"from finally\" }", "from finally"},
+		// an uncaught error still aborts
+		{"try { throw \"boom\" } finally { 1 }", "boom"},
+		{"try { throw \"a\" } catch (e) { throw \"b\" }", "b"},
+		{"try { throw \"a\" } catch (e) { throw e }", "a"},
+		// the binding doesn't leak out of the catch block
+		{"let e = 1; try { throw 2 } catch (e) { e }; e", 1},
+		{"throw 42", "42"},
+		{"throw x", "Identifier not found: x"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestCaughtErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { x } catch (e) { e.message }", "Identifier not found: x"},
+		{"try { x } catch (e) { e.kind }", "NameError"},
+		{"try { 1 + true } catch (e) { e.kind }", "TypeError"},
+		{"try { 1 / 0 } catch (e) { e.kind }", "ZeroDivisionError"},
+		{`try { "ab" * 9223372036854775807 } catch (e) { e.kind }`, "ValueError"},
+		{"try { 1 << -2 } catch (e) { e.kind }", "ValueError"},
+		{"try { [1, 2][::0] } catch (e) { e.kind }", "ValueError"},
+		{"try { pop([]) } catch (e) { e.kind }", "IndexError"},
+		{"try { remove([1], 1) } catch (e) { e.kind }", "IndexError"},
+		{"let f = fn(a) { a }; try { f() } catch (e) { e.kind }", "ArgumentError"},
+		{"try { match (1) { 2 => 3 } } catch (e) { e.kind }", "MatchError"},
+		{"try { throw \"boom\" } catch (e) { e.kind }", "Error"},
+		{"try { throw {\"message\": \"custom\", \"kind\": \"ParseError\"} } catch (e) { e.kind + e.message }", "ParseErrorcustom"},
+		{"try { throw error(\"bad\") } catch (e) { e.message }", "bad"},
+		{"try { throw true } catch (e) { e.message }", "true"},
+		{"let g = fn() { x }; let f = fn(a) { g() }; try { f(1) } catch (e) { e.stack[0] + \" \" + e.stack[1] }", "g() f(a)"},
+		// rethrowing keeps the kind and the stack
+		{"let f = fn() { x }; try { try { f() } catch (e) { throw e } } catch (e) { e.kind + \" \" + e.stack[0] }", "NameError f()"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, str.Value)
+		}
+	}
+}
+
+func TestErrorInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x", "NameError: Identifier not found: x"},
+		{"throw \"boom\"", "Error: boom"},
+		{"let f = fn(a) { a + true }; f(1)", "TypeError: Type mismatch: INT + BOOL\n    in f(a)"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"z": 1, "a": 2, 10: 3, true: 4, "m": 5}`, "{z: 1, a: 2, 10: 3, true: 4, m: 5}"},
+		{`let h = {"b": 1, "a": 2}; h["c"] = 3; h["b"] = 4; h`, "{b: 4, a: 2, c: 3}"},
+		{`{"a": 1, "b": 2, "a": 3}`, "{a: 3, b: 2}"},
+		// keys and values are evaluated in source order
+		{`let c = {"n": 0}; let f = fn(x) { c.n = c.n * 10 + x; x }; {f(1): f(2), f(3): f(4)}; c.n`, "1234"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEqualityExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" + "b" == "ab"`, true},
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{"[1, [2, [3]]] == [1, [2, [3]]]", true},
+		{"[1, 2] != [1, 2]", false},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{1: true} == {1: true, 2: false}`, false},
+		{"let n = if (false) { 1 }; n == n", true},
+		{"let f = fn() { 1 }; f == f", true},
+		{"fn() { 1 } == fn() { 1 }", false},
+		{"len == len", true},
+		// different types are never equal
+		{`1 == "1"`, false},
+		{`1 != "1"`, true},
+		{"[1] == 1", false},
+		{"true == 1", false},
+		{"let n = if (false) { 1 }; n == false", false},
+		// cycles
+		{"let a = [1, 0]; a[1] = a; let b = [1, 0]; b[1] = b; a == b", true},
+		{"let a = [1, 0]; a[1] = a; let b = [2, 0]; b[1] = b; a == b", false},
+		{`let a = {"x": 1}; a.self = a; let b = {"x": 1}; b.self = b; a == b`, true},
+		// identity
+		{"let a = [1]; a is a", true},
+		{"[1] is [1]", false},
+		{`let a = {"x": 1}; let b = a; b is a`, true},
+		{`{} is {}`, false},
+		{"1 is 1", true},
+		{`"a" is "a"`, true},
+		{`1 is "1"`, false},
+		{"let f = fn() { 1 }; f is f", true},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBoolObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestSequenceOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2] + [3]", "[1, 2, 3]"},
+		{"[] + []", "[]"},
+		{"[0] * 3", "[0, 0, 0]"},
+		{"2 * [1, 2]", "[1, 2, 1, 2]"},
+		{"[1] * -1", "[]"},
+		{"[] * 9223372036854775807", "[]"},
+		{`"" * 9223372036854775807`, ""},
+		{`len("ab" * 8388608)`, "16777216"},
+		{`"ab" * 3`, "ababab"},
+		{`"ab" * 0`, ""},
+		{`"héllo"[1]`, "é"},
+		{`"abc"[-1]`, "c"},
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][:-1]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][1::2]", "[2, 4]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3, 4, 5][3:0:-1]", "[4, 3, 2]"},
+		{"[1, 2, 3, 4, 5][-1:-3:-1]", "[5, 4]"},
+		{"[1, 2, 3][10:]", "[]"},
+		{"[1, 2, 3][-10:10]", "[1, 2, 3]"},
+		{"[1, 2, 3][2:1]", "[]"},
+		{`"hello"[1:4]`, "ell"},
+		{`"héllo"[::-1]`, "olléh"},
+		{`"hello"[-3:]`, "llo"},
+		{"let xs = [1, 2, 3]; let ys = xs[:]; ys[0] = 9; xs", "[1, 2, 3]"},
+		{"2 in [1, 2, 3]", "true"},
+		{"4 in [1, 2, 3]", "false"},
+		{"[1] in [[1], [2]]", "true"},
+		{`"ell" in "hello"`, "true"},
+		{`"z" in "hello"`, "false"},
+		{`"a" in {"a": 1}`, "true"},
+		{`1 in {"1": 1}`, "false"},
+		{"1 + 1 in [2]", "true"},
+		{"[1, 2][1:] == [2]", "true"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSequenceOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2][::0]", "Slice step cannot be zero"},
+		{`[1, 2]["a":]`, "Slice bounds must be INT, got STRING"},
+		{"5[1:2]", "Slice operator not supported: INT"},
+		{`1 in "abc"`, "Type mismatch: INT in STRING, expected a STRING"},
+		{"1 in 2", "Unknown operator: INT in INT"},
+		{"[1] in {}", "Unusable as hashkey: LIST"},
+		{`let s = "abc"; s[0] = "x"`, "Index assignment not supported: STRING, strings are immutable"},
+		{"[1] + 1", "Type mismatch: LIST + INT"},
+		{"[1] * [1]", "Unknown operator: LIST * LIST"},
+		{`"ab" * 9223372036854775807`, "Repeat result too large: a STRING of 2 bytes * 9223372036854775807 is over 16777216 bytes"},
+		{"[1] * 4611686018427387904", "Repeat result too large: a LIST of 1 elements * 4611686018427387904 is over 16777216 elements"},
+		{"[1, 2] * 8388609", "Repeat result too large: a LIST of 2 elements * 8388609 is over 16777216 elements"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"use strict"; [1, 2, 3][3]`, "Index out of range: 3, length is 3"},
+		{`"use strict"; [1, 2, 3][-4]`, "Index out of range: -4, length is 3"},
+		{`"use strict"; [1, 2, 3][-1]`, 3},
+		{`"use strict"; let xs = [1]; xs[1] = 2`, "Index out of range: 1, length is 1"},
+		{`"use strict"; "abc"[5]`, "Index out of range: 5, length is 3"},
+		{`"use strict"; {"a": 1}["b"]`, `Key not found: "b"`},
+		{`"use strict"; {"a": 1}.b`, `Key not found: "b"`},
+		{`"use strict"; let h = {}; h.b += 1`, `Key not found: "b"`},
+		{`"use strict"; {"a": 1}[1]`, "Key not found: 1"},
+		{`"use strict"; let h = {}; h.b = 1; h.b`, 1},
+		// functions defined in strict code are strict
+		{`"use strict"; let f = fn(xs) { xs[5] }; f([])`, "Index out of range: 5, length is 0"},
+		// the pragma only applies to its function
+		{`let f = fn(xs) { "use strict"; xs[5] }; f([])`, "Index out of range: 5, length is 0"},
+		{`let f = fn(xs) { "use strict"; 1 }; f([]); [][5]`, nil},
+		// not the first statement, so not a pragma
+		{`1; "use strict"; [][0]`, nil},
+		// destructuring stays lenient
+		{`"use strict"; let [a, b] = [1]; let {c} = {}; b`, nil},
+		{`"use strict"; try { [][0] } catch (e) { e.kind == "IndexError" }`, true},
+		// the index type is checked in both modes
+		{`[1, 2]["a"]`, "LIST index must be INT, got STRING"},
+		{`"ab"[true]`, "STRING index must be INT, got BOOL"},
+		// get is lenient in both modes
+		{`"use strict"; get([1, 2], 5, 0)`, 0},
+		{`"use strict"; get([1, 2], -1)`, 2},
+		{`get({"a": 1}, "b", 7)`, 7},
+		{`get({"a": 1}, "a", 7)`, 1},
+		{`get([1], 5)`, nil},
+		{`get("abc", 10, 1)`, 1},
+		{`get([1], "a", 1)`, "LIST index must be INT, got STRING"},
+		{`get(1, 1)`, `Builtin "get" doesn't take INT args`},
+		{`get([1])`, `Builtin "get": wrong number of args. got=1, expected=2 to 3`},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBoolObject(t, evaluated, expected)
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestStrictEnv(t *testing.T) {
+	l := lexer.New(`let h = {}; h["missing"]`)
+	p := parser.New(l)
+	program := p.Parse()
+	env := object.NewEnv()
+	env.SetStrict(true)
+
+	evaluated := Eval(program, env)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	if errObj.Kind != object.KEY_ERROR {
+		t.Errorf("wrong error kind. got=%q", errObj.Kind)
+	}
+}
+
+func TestListBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let xs = [1]; push(xs, 2, 3); xs", "[1, 2, 3]"},
+		{"push([], 1)", "[1]"},
+		{"let xs = [1, 2]; let x = pop(xs); [x, xs]", "[2, [1]]"},
+		{`let xs = [1, 2]; insert(xs, 1, "a"); xs`, "[1, a, 2]"},
+		{`insert([1, 2], -1, "a")`, "[1, a, 2]"},
+		{`insert([1, 2], 10, "a")`, "[1, 2, a]"},
+		{`insert([1, 2], -10, "a")`, "[a, 1, 2]"},
+		{"let xs = [1, 2, 3]; let x = remove(xs, 0); [x, xs]", "[1, [2, 3]]"},
+		{"let xs = [1, 2, 3]; remove(xs, -1); xs", "[1, 2]"},
+		{`let h = {"a": 1, "b": 2}; let x = remove(h, "a"); [x, h]`, "[1, {b: 2}]"},
+		{`remove({}, "a")`, "null"},
+		{"index_of([1, 2, 3], 2)", "1"},
+		{"index_of([1, [2]], [2])", "1"},
+		{"index_of([1, 2, 3], 4)", "-1"},
+		{"contains([1, 2, 3], 3)", "true"},
+		{"contains([], 3)", "false"},
+		{"let xs = [1, 2, 3]; [reverse(xs), xs]", "[[3, 2, 1], [1, 2, 3]]"},
+		{"concat([1], [2, 3], [])", "[1, 2, 3]"},
+		{"concat()", "[]"},
+		{"slice([1, 2, 3, 4], 1, 3)", "[2, 3]"},
+		{"slice([1, 2, 3, 4], -2)", "[3, 4]"},
+		{"first([1, 2])", "1"},
+		{"first([])", "null"},
+		{"last([1, 2])", "2"},
+		{"last([])", "null"},
+		{"rest([1, 2, 3])", "[2, 3]"},
+		{"rest([])", "[]"},
+		{"let xs = [3, 1, 2]; [sort(xs), xs]", "[[1, 2, 3], [3, 1, 2]]"},
+		{`sort(["b", "c", "a"])`, "[a, b, c]"},
+		{"sort([3, 1, 2], fn(a, b) { a > b })", "[3, 2, 1]"},
+		{"sort([3, 1, 2], fn(a, b) { b - a })", "[3, 2, 1]"},
+		// stable : equal elements keep their order
+		{`sort([[2, "a"], [1, "b"], [2, "c"], [1, "d"]], fn(a, b) { a[0] < b[0] })`, "[[1, b], [1, d], [2, a], [2, c]]"},
+		{"sort([])", "[]"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestListBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"push([])", `Builtin "push": wrong number of args. got=1, expected=at least 2`},
+		{"push(1, 2)", `Builtin "push" expected a LIST, got INT`},
+		{"pop([], 1)", `Builtin "pop": wrong number of args. got=2, expected=1`},
+		{"pop([])", "Cannot pop from an empty list"},
+		{`insert([], "a", 1)`, `Builtin "insert" expected an INT, got STRING`},
+		{"remove([1], 1)", "Index out of range: 1, length is 1"},
+		{"remove([1], [1])", `Builtin "remove" expected an INT, got LIST`},
+		{"remove({}, [1])", "Unusable as hashkey: LIST"},
+		{"slice([1])", `Builtin "slice": wrong number of args. got=1, expected=2 to 3`},
+		{"concat([1], 2)", `Builtin "concat" expected a LIST, got INT`},
+		{`sort([1, "a"])`, `Builtin "sort" can't compare STRING and INT without a comparator`},
+		{`sort([1, 2], fn(a, b) { "x" })`, `Builtin "sort": comparator must return a BOOL or an INT, got STRING`},
+		{"sort([1, 2], fn(a, b) { c })", "Identifier not found: c"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"map([], fn(x) { x })", "[]"},
+		{`map(["a", "bc"], len)`, "[1, 2]"},
+		{`map({"a": 1, "b": 2}, fn(k, v) { k + ":" })`, "[a:, b:]"},
+		{"let n = 10; map([1, 2], fn(x) { x + n })", "[11, 12]"},
+		{"filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })", "[2, 4]"},
+		{`filter({"a": 1, "b": 2, "c": 3}, fn(k, v) { v != 2 })`, "{a: 1, c: 3}"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc + x }, 10)", "16"},
+		{"reduce([1, 2, 3], fn(acc, x) { acc * x })", "6"},
+		{"reduce([], fn(acc, x) { acc + x }, 0)", "0"},
+		{`reduce({"a": 1, "b": 2}, fn(acc, k, v) { acc + v }, 0)`, "3"},
+		{"let sum = [0]; each([1, 2, 3], fn(x) { sum[0] += x }); sum[0]", "6"},
+		{"each([1], fn(x) { x })", "null"},
+		{"any([1, 2, 3], fn(x) { x > 2 })", "true"},
+		{"any([1, 2, 3], fn(x) { x > 3 })", "false"},
+		{"any([], fn(x) { true })", "false"},
+		{"all([1, 2, 3], fn(x) { x > 0 })", "true"},
+		{"all([1, 2, 3], fn(x) { x > 1 })", "false"},
+		{"all([], fn(x) { false })", "true"},
+		{`all({"a": 1}, fn(k, v) { v == 1 })`, "true"},
+		{"find([1, 2, 3, 4], fn(x) { x > 2 })", "3"},
+		{"find([1, 2], fn(x) { x > 2 })", "null"},
+		{`find({"a": 1, "b": 2}, fn(k, v) { v == 2 })`, "[b, 2]"},
+		// stops at the first match
+		{"let calls = [0]; find([1, 2, 3], fn(x) { calls[0] += 1; x == 2 }); calls[0]", "2"},
+		{"zip([1, 2, 3], [4, 5])", "[[1, 4], [2, 5]]"},
+		{`zip([1], ["a"], [true])`, "[[1, a, true]]"},
+		{"zip([])", "[]"},
+		{"group_by([1, 2, 3, 4, 5], fn(x) { x % 2 })", "{1: [1, 3, 5], 0: [2, 4]}"},
+		{`group_by(["ab", "c", "de"], len)`, "{2: [ab, de], 1: [c]}"},
+		{`group_by({"a": 1, "b": 2, "c": 1}, fn(k, v) { v })`, "{1: [[a, 1], [c, 1]], 2: [[b, 2]]}"},
+		{"flat_map([1, 2], fn(x) { [x, x * 10] })", "[1, 10, 2, 20]"},
+		{"flat_map([1, 2], fn(x) { x })", "[1, 2]"},
+		// the callback can change the collection, the iteration uses the elements it started with
+		{"let xs = [1, 2]; map(xs, fn(x) { push(xs, x) }); xs", "[1, 2, 1, 2]"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map(1, fn(x) { x })", `Builtin "map" expected a LIST or a HASH, got INT`},
+		{"map([1])", `Builtin "map": wrong number of args. got=1, expected=2`},
+		{"map([1], 1)", "1 isn't a function (user defined or builtin)."},
+		{`map({"a": 1}, fn(x) { x })`, "Wrong number of args to fn(x): expected 1, given 2"},
+		{"filter([1], fn(x) { y })", "Identifier not found: y"},
+		{"reduce([], fn(acc, x) { acc })", `Builtin "reduce" of an empty LIST needs an initial value`},
+		{"reduce({}, fn(acc, k, v) { acc })", `Builtin "reduce" needs an initial value for a HASH`},
+		{"group_by([1], fn(x) { [x] })", "Unusable as hashkey: LIST"},
+		{"zip([1], 2)", `Builtin "zip" expected a LIST, got INT`},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestCallbackErrorStack(t *testing.T) {
+	input := `let check = fn(x) { if (x > 1) { throw "too big" }; x }
+let run = fn(xs) { map(xs, check) }
+run([1, 2])`
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	expected := []string{"check(x)", "map", "run(xs)"}
+	if strings.Join(errObj.Stack, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("wrong stack. expected=%v, got=%v", expected, errObj.Stack)
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("héllo")`, 5},
+		{`len("日本語")`, 3},
+		{`split("a,b,,c", ",")`, "[a, b, , c]"},
+		{`split("  a b	c ")`, "[a, b, c]"},
+		{`split("hé", "")`, "[h, é]"},
+		{`join(["a", "b", "c"], ", ")`, "a, b, c"},
+		{`join([1, true, "x"])`, "1truex"},
+		{`join([], "-")`, ""},
+		{`trim("  hi  ")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`trim_left("  hi  ")`, "hi  "},
+		{`trim_right("  hi  ")`, "  hi"},
+		{`trim_right("hi!!", "!")`, "hi"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("HÉLLO")`, "héllo"},
+		{`replace("aaa", "a", "b")`, "bbb"},
+		{`replace("aaa", "a", "b", 2)`, "bba"},
+		{`starts_with("hello", "he")`, "true"},
+		{`starts_with("hello", "lo")`, "false"},
+		{`ends_with("hello", "lo")`, "true"},
+		{`index_of("héllo", "l")`, 2},
+		{`index_of("hello", "z")`, -1},
+		{`index_of([1, 2], 2)`, 1},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", -1)`, ""},
+		{`pad_left("7", 3, "0")`, "007"},
+		{`pad_left("é", 3)`, "  é"},
+		{`pad_right("a", 3, "é")`, "aéé"},
+		{`pad_left("long", 2)`, "long"},
+		{`chars("héllo")`, "[h, é, l, l, o]"},
+		{`chars("")`, "[]"},
+		{`ord("a")`, 97},
+		{`ord("é")`, 233},
+		{`chr(233)`, "é"},
+		{`chr(ord("a") + 1)`, "b"},
+		{`map(split("a b"), upper)`, "[A, B]"},
+		// errors
+		{`repr(["1", 1, "a\"b"])`, `["1", 1, "a\"b"]`},
+		{`let add = fn(a, b) { a + b }; repr({"f": add, "g": fn(x) { x }, "len": len})`, `{"f": <fn add(a, b)>, "g": <fn(x)>, "len": <builtin len>}`},
+		{`let xs = [1]; push(xs, xs); repr(xs) + " " + join(xs, ",")`, `[1, [...]] 1,[1, [...]]`},
+		{`repeat("ab", 9223372036854775807)`, `Builtin "repeat": the result would be over 16777216 bytes`},
+		{`pad_left("a", 9223372036854775807)`, `Builtin "pad_left": the result would be over 16777216 bytes`},
+		{`pad_right("a", 100000000000)`, `Builtin "pad_right": the result would be over 16777216 bytes`},
+		{`pad_left("a", 9223372036854775807, "é")`, `Builtin "pad_left": the result would be over 16777216 bytes`},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`len(pad_left("a", 16777216))`, 16777216},
+		{`upper(1)`, `Builtin "upper" expected a STRING, got INT`},
+		{`split("a", 1)`, `Builtin "split" expected a STRING, got INT`},
+		{`join("a")`, `Builtin "join" expected a LIST, got STRING`},
+		{`pad_left("a", 3, "ab")`, `Builtin "pad_left": the padding must be one character, got "ab"`},
+		{`ord("ab")`, `Builtin "ord" expected one character, got "ab"`},
+		{`chr(-1)`, `Builtin "chr": -1 isn't a valid character code`},
+		{`replace("a", "a")`, `Builtin "replace": wrong number of args. got=2, expected=3 to 4`},
+		{`index_of("a", 1)`, `Builtin "index_of" expected a STRING, got INT`},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if errObj, ok := evaluated.(*object.Error); ok {
+				if errObj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+				}
+				continue
+			}
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "trash"; "Hello ${name}!"`, "Hello trash!"},
+		{`let items = [1, 2]; "you have ${len(items)} items"`, "you have 2 items"},
+		{`"${1 + 2}${true}${[1, "a"]}"`, "3true[1, a]"},
+		{`"${{"a": 1}.a}"`, "1"},
+		{`let f = fn(x) { "<${x}>" }; "${f("y")}"`, "<y>"},
+		{`"no ${"nested ${1}"} problem"`, "no nested 1 problem"},
+		{`"${if (false) { 1 }}"`, "null"},
+		{`"\${literal}"`, "${literal}"},
+		{`"a ${x} b"`, "Identifier not found: x"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if errObj, ok := evaluated.(*object.Error); ok {
+			if errObj.Message != tt.expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+			}
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
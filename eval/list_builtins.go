@@ -0,0 +1,379 @@
+/*
+List built-in functions :-
+
+These change the list they're given (and return it, or the removed element) :
+
+	push(xs, 4, 5)     // adds to the end, returns xs
+	pop(xs)            // removes the last element and returns it, an IndexError if xs is empty
+	insert(xs, 1, "a") // inserts before the index (negative counts from the end, out of range is clamped), returns xs
+	remove(xs, 1)      // removes the element at the index and returns it, an IndexError if it's out of range
+	remove(hash, key)  // removes the key and returns its value, null if it isn't there
+
+These return a new list, and don't change the ones they're given :
+
+	reverse(xs), concat(xs, ys, ...), slice(xs, start, end), rest(xs), sort(xs), sort(xs, fn(a, b) { a > b })
+
+And the rest only read :
+
+	index_of(xs, x) // -1 if it's not there, elements are compared with == (it also finds substrings in strings)
+	contains(xs, x)
+	first(xs), last(xs) // null if xs is empty
+
+sort is stable, without a comparator it sorts ints or strings in ascending order.
+The comparator returns true (or a negative int) if a goes before b.
+
+They're registered in init() because sort calls back into the evaluator, which reads the builtins map.
+*/
+package eval
+
+import (
+	"sort"
+	"strconv"
+	"trash/object"
+)
+
+func init() {
+	registerBuiltins(listBuiltins)
+}
+
+func checkArgs(name string, args []object.Object, min, max int) *object.Error {
+	if len(args) < min || len(args) > max {
+		expected := strconv.Itoa(min)
+		if max != min {
+			expected += " to " + strconv.Itoa(max)
+		}
+		return newKindErr(object.ARG_ERROR, `Builtin "%s": wrong number of args. got=%d, expected=%s`, name, len(args), expected)
+	}
+	return nil
+}
+
+func checkArgsAtLeast(name string, args []object.Object, min int) *object.Error {
+	if len(args) < min {
+		return newKindErr(object.ARG_ERROR, `Builtin "%s": wrong number of args. got=%d, expected=at least %d`, name, len(args), min)
+	}
+	return nil
+}
+
+func listArg(name string, arg object.Object) (*object.List, *object.Error) {
+	list, ok := arg.(*object.List)
+	if !ok {
+		return nil, newKindErr(object.TYPE_ERROR, `Builtin "%s" expected a LIST, got %s`, name, arg.Type())
+	}
+	return list, nil
+}
+
+func intArg(name string, arg object.Object) (int64, *object.Error) {
+	i, ok := arg.(*object.Int)
+	if !ok {
+		return 0, newKindErr(object.TYPE_ERROR, `Builtin "%s" expected an INT, got %s`, name, arg.Type())
+	}
+	return i.Value, nil
+}
+
+var listBuiltins = map[string]*object.Builtin{
+	"push": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgsAtLeast("push", args, 2); err != nil {
+				return err
+			}
+			list, err := listArg("push", args[0])
+			if err != nil {
+				return err
+			}
+			list.Values = append(list.Values, args[1:]...)
+			return list
+		},
+	},
+	"pop": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("pop", args, 1, 1); err != nil {
+				return err
+			}
+			list, err := listArg("pop", args[0])
+			if err != nil {
+				return err
+			}
+			if len(list.Values) == 0 {
+				return newKindErr(object.INDEX_ERROR, "Cannot pop from an empty list")
+			}
+			last := list.Values[len(list.Values)-1]
+			list.Values = list.Values[:len(list.Values)-1]
+			return last
+		},
+	},
+	"insert": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("insert", args, 3, 3); err != nil {
+				return err
+			}
+			list, err := listArg("insert", args[0])
+			if err != nil {
+				return err
+			}
+			idx, err := intArg("insert", args[1])
+			if err != nil {
+				return err
+			}
+			length := int64(len(list.Values))
+			if idx < 0 {
+				idx += length
+			}
+			if idx < 0 {
+				idx = 0
+			} else if idx > length {
+				idx = length
+			}
+			list.Values = append(list.Values, nil)
+			copy(list.Values[idx+1:], list.Values[idx:])
+			list.Values[idx] = args[2]
+			return list
+		},
+	},
+	"remove": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("remove", args, 2, 2); err != nil {
+				return err
+			}
+			if hash, ok := args[0].(*object.Hashmap); ok {
+				key, ok := args[1].(object.Hashable)
+				if !ok {
+					return newKindErr(object.TYPE_ERROR, "Unusable as hashkey: %s", args[1].Type())
+				}
+				val, found := hash.Get(key)
+				if !found {
+					return NULL
+				}
+				hash.Delete(key)
+				return val
+			}
+			list, err := listArg("remove", args[0])
+			if err != nil {
+				return err
+			}
+			idx, err := intArg("remove", args[1])
+			if err != nil {
+				return err
+			}
+			length := int64(len(list.Values))
+			if idx < 0 {
+				idx += length
+			}
+			if idx < 0 || idx >= length {
+				return indexErr(args[1], length)
+			}
+			removed := list.Values[idx]
+			list.Values = append(list.Values[:idx], list.Values[idx+1:]...)
+			return removed
+		},
+	},
+	"index_of": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("index_of", args, 2, 2); err != nil {
+				return err
+			}
+			if s, ok := args[0].(*object.String); ok {
+				sub, err := strArg("index_of", args[1])
+				if err != nil {
+					return err
+				}
+				return &object.Int{Value: int64(stringIndexOf(s.Value, sub))}
+			}
+			list, err := listArg("index_of", args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Int{Value: int64(indexOf(list, args[1]))}
+		},
+	},
+	"contains": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("contains", args, 2, 2); err != nil {
+				return err
+			}
+			list, err := listArg("contains", args[0])
+			if err != nil {
+				return err
+			}
+			return mapBool(indexOf(list, args[1]) != -1)
+		},
+	},
+	"reverse": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("reverse", args, 1, 1); err != nil {
+				return err
+			}
+			list, err := listArg("reverse", args[0])
+			if err != nil {
+				return err
+			}
+			reversed := make([]object.Object, len(list.Values))
+			for i, val := range list.Values {
+				reversed[len(list.Values)-1-i] = val
+			}
+			return &object.List{Values: reversed}
+		},
+	},
+	"concat": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			values := []object.Object{}
+			for _, arg := range args {
+				list, err := listArg("concat", arg)
+				if err != nil {
+					return err
+				}
+				values = append(values, list.Values...)
+			}
+			return &object.List{Values: values}
+		},
+	},
+	// slice(xs, start, end) is xs[start:end]
+	"slice": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("slice", args, 2, 3); err != nil {
+				return err
+			}
+			list, err := listArg("slice", args[0])
+			if err != nil {
+				return err
+			}
+			var end object.Object
+			if len(args) == 3 {
+				end = args[2]
+			}
+			for _, bound := range args[1:] {
+				if _, err := intArg("slice", bound); err != nil {
+					return err
+				}
+			}
+			indices, err := sliceIndices(int64(len(list.Values)), args[1], end, nil)
+			if err != nil {
+				return err
+			}
+			sliced := make([]object.Object, 0, len(indices))
+			for _, i := range indices {
+				sliced = append(sliced, list.Values[i])
+			}
+			return &object.List{Values: sliced}
+		},
+	},
+	"first": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("first", args, 1, 1); err != nil {
+				return err
+			}
+			list, err := listArg("first", args[0])
+			if err != nil {
+				return err
+			}
+			if len(list.Values) == 0 {
+				return NULL
+			}
+			return list.Values[0]
+		},
+	},
+	"last": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("last", args, 1, 1); err != nil {
+				return err
+			}
+			list, err := listArg("last", args[0])
+			if err != nil {
+				return err
+			}
+			if len(list.Values) == 0 {
+				return NULL
+			}
+			return list.Values[len(list.Values)-1]
+		},
+	},
+	// everything but the first element, an empty list if there's none
+	"rest": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("rest", args, 1, 1); err != nil {
+				return err
+			}
+			list, err := listArg("rest", args[0])
+			if err != nil {
+				return err
+			}
+			if len(list.Values) == 0 {
+				return &object.List{Values: []object.Object{}}
+			}
+			return &object.List{Values: append([]object.Object{}, list.Values[1:]...)}
+		},
+	},
+	"sort": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("sort", args, 1, 2); err != nil {
+				return err
+			}
+			list, err := listArg("sort", args[0])
+			if err != nil {
+				return err
+			}
+			var comparator object.Object
+			if len(args) == 2 {
+				comparator = args[1]
+			}
+			return sortList(ctx, list, comparator)
+		},
+	},
+}
+
+func indexOf(list *object.List, val object.Object) int {
+	for i, item := range list.Values {
+		if object.Equal(item, val) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortList(ctx *object.Context, list *object.List, comparator object.Object) object.Object {
+	sorted := append([]object.Object{}, list.Values...)
+
+	// the first error stops the comparisons, sort.SliceStable can't be interrupted so the rest are skipped
+	var sortErr object.Object
+	less := func(a, b object.Object) bool {
+		if sortErr != nil {
+			return false
+		}
+		if comparator == nil {
+			return defaultLess(a, b, &sortErr)
+		}
+		res := ctx.Call(comparator, a, b)
+		switch res := res.(type) {
+		case *object.Error:
+			sortErr = res
+			return false
+		case *object.Bool:
+			return res.Value
+		case *object.Int:
+			return res.Value < 0
+		default:
+			sortErr = newKindErr(object.TYPE_ERROR, `Builtin "sort": comparator must return a BOOL or an INT, got %s`, res.Type())
+			return false
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	if sortErr != nil {
+		return sortErr
+	}
+	return &object.List{Values: sorted}
+}
+
+func defaultLess(a, b object.Object, sortErr *object.Object) bool {
+	switch a := a.(type) {
+	case *object.Int:
+		if b, ok := b.(*object.Int); ok {
+			return a.Value < b.Value
+		}
+	case *object.String:
+		if b, ok := b.(*object.String); ok {
+			return a.Value < b.Value
+		}
+	}
+	*sortErr = newKindErr(object.TYPE_ERROR, `Builtin "sort" can't compare %s and %s without a comparator`, a.Type(), b.Type())
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+match (value) { pattern => expr, ... }
+
+The arms are tried in order, the first arm whose pattern matches (and whose guard is truthy) is evaluated.
+Each arm gets a fresh env enclosed by the current one, so the names bound by the pattern don't leak out of the arm.
+
+	match (shape) {
+		{"kind": "circle", r} => 3 * r * r,
+		{"kind": "rect", w, h} if w == h => "square",
+		[first, ...rest] => first,
+		int => "a number",
+		_ => "something else"
+	}
+
+Unlike let patterns, a list pattern without a rest only matches lists of the same length,
+and a hash pattern only matches hashmaps that have all of its keys.
+*/
+package eval
+
+import (
+	"trash/ast"
+	"trash/object"
+)
+
+// the object types of each type pattern, the names are ast.TypePatternNames and fn
+var typePatterns = map[string][]object.ObjectType{
+	"int":    {object.INT_OBJ},
+	"string": {object.STR_OBJ},
+	"bool":   {object.BOOL_OBJ},
+	"null":   {object.NULL_OBJ},
+	"list":   {object.LIST_OBJ},
+	"hash":   {object.HASHMAP_OBJ},
+	"fn":     {object.FUNC_OBJ, object.BUILTIN_OBJ},
+}
+
+// the wildcard pattern, matches anything without binding it
+const WILDCARD = "_"
+
+func evalMatchExpression(node *ast.MatchExpression, env *object.Env) object.Object {
+	subject := Eval(node.Subject, env)
+	if isErr(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnclosedEnv(env)
+		if !matchPattern(arm.Pattern, subject, armEnv) {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isErr(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		return Eval(arm.Body, armEnv)
+	}
+	return newKindErr(object.MATCH_ERROR, "No match arm for value: %s", object.Repr(subject))
+}
+
+// checks if the value has the shape of the pattern, binding the names in the pattern into env on the way
+func matchPattern(pattern ast.Expression, val object.Object, env *object.Env) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != WILDCARD {
+			env.Set(pattern.Value, val)
+		}
+		return true
+
+	case *ast.IntegerLiteral:
+		i, ok := val.(*object.Int)
+		return ok && i.Value == pattern.Value
+
+	// negative numbers : -1
+	case *ast.PrefixExpression:
+		lit, ok := pattern.Right.(*ast.IntegerLiteral)
+		i, isInt := val.(*object.Int)
+		return ok && isInt && pattern.Operator == "-" && i.Value == -lit.Value
+
+	case *ast.StringLiteral:
+		str, ok := val.(*object.String)
+		return ok && str.Value == pattern.Value
+
+	case *ast.Boolean:
+		return val == mapBool(pattern.Value)
+
+	case *ast.TypePattern:
+		for _, t := range typePatterns[pattern.Name] {
+			if val.Type() == t {
+				return true
+			}
+		}
+		return false
+
+	case *ast.ListPattern:
+		list, ok := val.(*object.List)
+		if !ok {
+			return false
+		}
+		if len(list.Values) < len(pattern.Elements) {
+			return false
+		}
+		if pattern.Rest == nil && len(list.Values) != len(pattern.Elements) {
+			return false
+		}
+		for i, el := range pattern.Elements {
+			if !matchPattern(el, list.Values[i], env) {
+				return false
+			}
+		}
+		if pattern.Rest != nil && pattern.Rest.Value != WILDCARD {
+			rest := append([]object.Object{}, list.Values[len(pattern.Elements):]...)
+			env.Set(pattern.Rest.Value, &object.List{Values: rest})
+		}
+		return true
+
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hashmap)
+		if !ok {
+			return false
+		}
+		for _, pair := range pattern.Pairs {
+			item, ok := hash.Get(&object.String{Value: pair.Key})
+			if !ok || !matchPattern(pair.Value, item, env) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Binding patterns to values, used by let and function params :
+
+	let [a, b = 2, ...rest] = [1]        // a = 1, b = 2, rest = []
+	let {name, age: years} = {"name": "trash", "age": 1}
+
+A missing element (or key) is NULL, unless the pattern has a default, then the default is evaluated in the same env,
+so it can use what's bound before it : let [a, b = a * 2] = [1]
+Only a missing value takes the default, an element (or a value, or an arg) that is null is kept.
+Extra elements are ignored, but binding a list pattern to a non list (or a hash pattern to a non hashmap) is an error.
+*/
+package eval
+
+import (
+	"trash/ast"
+	"trash/object"
+)
+
+// binds the names in the pattern into env, returns an error object if the value doesn't have the shape of the pattern.
+// val is nil when the value is missing (an element past the end, a key that isn't there, an arg that isn't given)
+func bindPattern(pattern ast.Expression, val object.Object, env *object.Env) object.Object {
+	if _, ok := pattern.(*ast.DefaultPattern); !ok && val == nil {
+		val = NULL
+	}
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Set(pattern.Value, val)
+		return nil
+
+	case *ast.DefaultPattern:
+		if val == nil {
+			val = Eval(pattern.Default, env)
+			if isErr(val) {
+				return val
+			}
+		}
+		return bindPattern(pattern.Target, val, env)
+
+	case *ast.ListPattern:
+		list, ok := val.(*object.List)
+		if !ok {
+			return newKindErr(object.TYPE_ERROR, "Cannot destructure %s as a list: %s", val.Type(), pattern.String())
+		}
+		for i, el := range pattern.Elements {
+			var item object.Object
+			if i < len(list.Values) {
+				item = list.Values[i]
+			}
+			if err := bindPattern(el, item, env); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := []object.Object{}
+			if len(list.Values) > len(pattern.Elements) {
+				rest = append(rest, list.Values[len(pattern.Elements):]...)
+			}
+			env.Set(pattern.Rest.Value, &object.List{Values: rest})
+		}
+		return nil
+
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hashmap)
+		if !ok {
+			return newKindErr(object.TYPE_ERROR, "Cannot destructure %s as a hashmap: %s", val.Type(), pattern.String())
+		}
+		for _, pair := range pattern.Pairs {
+			// missing keys bind NULL (or the default) even in strict mode
+			item, _ := hash.Get(&object.String{Value: pair.Key})
+			if err := bindPattern(pair.Value, item, env); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return newErr("Invalid pattern: %s", pattern.String())
+	}
+}
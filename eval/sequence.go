@@ -0,0 +1,220 @@
+/*
+Operators on lists and strings :-
+
+	[1, 2] + [3]      // [1, 2, 3], a new list
+	[0] * 3           // [0, 0, 0], a negative count gives an empty list
+	"ab" * 2          // abab
+	xs[-1]            // the last element
+	xs[1:3], xs[:-1]  // slices, a new list (or string) from start up to (not including) end
+	xs[::2], xs[::-1] // every second element, reversed
+	2 in [1, 2]       // true, using == on the elements
+	"ell" in "hello"  // true, substring
+	"a" in {"a": 1}   // true, hashmap keys
+
+Slices work like Python's, out of range bounds are clamped instead of being errors.
+Repeating is limited to MAX_REPEAT_SIZE, a bigger result is a ValueError instead of running out of memory.
+Strings are indexed and sliced by runes (characters), not bytes.
+*/
+package eval
+
+import (
+	"strings"
+	"trash/ast"
+	"trash/object"
+)
+
+func isSequence(obj object.Object) bool {
+	return obj.Type() == object.LIST_OBJ || obj.Type() == object.STR_OBJ
+}
+
+func evalListConcat(left, right object.Object) object.Object {
+	leftVals := left.(*object.List).Values
+	rightVals := right.(*object.List).Values
+
+	values := make([]object.Object, 0, len(leftVals)+len(rightVals))
+	values = append(values, leftVals...)
+	values = append(values, rightVals...)
+	return &object.List{Values: values}
+}
+
+// the biggest string (in bytes) or list (in elements) that repeating or padding can make
+const MAX_REPEAT_SIZE = 1 << 24
+
+// checks that size repeated count times stays under MAX_REPEAT_SIZE, without overflowing
+func repeatFits(size, count int64) bool {
+	return count <= 0 || size <= MAX_REPEAT_SIZE/count
+}
+
+func evalRepeat(seq, count object.Object) object.Object {
+	n := count.(*object.Int).Value
+	if n < 0 {
+		n = 0
+	}
+
+	switch seq := seq.(type) {
+	case *object.String:
+		if !repeatFits(int64(len(seq.Value)), n) {
+			return newKindErr(object.VALUE_ERROR, "Repeat result too large: a STRING of %d bytes * %d is over %d bytes", len(seq.Value), n, MAX_REPEAT_SIZE)
+		}
+		return &object.String{Value: strings.Repeat(seq.Value, int(n))}
+	default:
+		values := seq.(*object.List).Values
+		if len(values) == 0 {
+			return &object.List{Values: []object.Object{}}
+		}
+		if !repeatFits(int64(len(values)), n) {
+			return newKindErr(object.VALUE_ERROR, "Repeat result too large: a LIST of %d elements * %d is over %d elements", len(values), n, MAX_REPEAT_SIZE)
+		}
+		repeated := make([]object.Object, 0, len(values)*int(n))
+		for i := int64(0); i < n; i++ {
+			repeated = append(repeated, values...)
+		}
+		return &object.List{Values: repeated}
+	}
+}
+
+func evalStringIndexExpression(str, index object.Object, strict bool) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Int).Value
+	length := int64(len(runes))
+
+	if idx < 0 {
+		idx += length
+	}
+	if idx < 0 || idx >= length {
+		if strict {
+			return indexErr(index, length)
+		}
+		return NULL
+	}
+	return &object.String{Value: string(runes[idx])}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Env) object.Object {
+	left := Eval(node.Left, env)
+	if isErr(left) {
+		return left
+	}
+	if !isSequence(left) {
+		return newKindErr(object.TYPE_ERROR, "Slice operator not supported: %s", left.Type())
+	}
+
+	// the omitted bounds stay nil
+	bounds := []object.Object{}
+	for _, exp := range []ast.Expression{node.Start, node.End, node.Step} {
+		if exp == nil {
+			bounds = append(bounds, nil)
+			continue
+		}
+		bound := Eval(exp, env)
+		if isErr(bound) {
+			return bound
+		}
+		if bound.Type() != object.INT_OBJ {
+			return newKindErr(object.TYPE_ERROR, "Slice bounds must be INT, got %s", bound.Type())
+		}
+		bounds = append(bounds, bound)
+	}
+
+	switch seq := left.(type) {
+	case *object.String:
+		runes := []rune(seq.Value)
+		indices, err := sliceIndices(int64(len(runes)), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		sliced := make([]rune, 0, len(indices))
+		for _, i := range indices {
+			sliced = append(sliced, runes[i])
+		}
+		return &object.String{Value: string(sliced)}
+
+	default:
+		values := seq.(*object.List).Values
+		indices, err := sliceIndices(int64(len(values)), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		sliced := make([]object.Object, 0, len(indices))
+		for _, i := range indices {
+			sliced = append(sliced, values[i])
+		}
+		return &object.List{Values: sliced}
+	}
+}
+
+// the indices picked by [start:end:step] in a sequence of the given length
+func sliceIndices(length int64, start, end, step object.Object) ([]int64, *object.Error) {
+	stepVal := int64(1)
+	if step != nil {
+		stepVal = step.(*object.Int).Value
+	}
+	if stepVal == 0 {
+		return nil, newKindErr(object.VALUE_ERROR, "Slice step cannot be zero")
+	}
+
+	// going backwards the first index is the last element, and the end can go up to -1 (before the first element)
+	lower, upper := int64(0), length
+	if stepVal < 0 {
+		lower, upper = -1, length-1
+	}
+	bound := func(obj object.Object, def int64) int64 {
+		if obj == nil {
+			return def
+		}
+		i := obj.(*object.Int).Value
+		if i < 0 {
+			i += length
+			if i < lower {
+				i = lower
+			}
+		} else if i > upper {
+			i = upper
+		}
+		return i
+	}
+
+	var startVal, endVal int64
+	if stepVal > 0 {
+		startVal, endVal = bound(start, lower), bound(end, upper)
+	} else {
+		startVal, endVal = bound(start, upper), bound(end, lower)
+	}
+
+	indices := []int64{}
+	for i := startVal; (stepVal > 0 && i < endVal) || (stepVal < 0 && i > endVal); i += stepVal {
+		indices = append(indices, i)
+	}
+	return indices, nil
+}
+
+// x in list, sub in str, key in hash
+func evalInExpression(left, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.List:
+		for _, val := range right.Values {
+			if object.Equal(left, val) {
+				return TRUE
+			}
+		}
+		return FALSE
+
+	case *object.String:
+		sub, ok := left.(*object.String)
+		if !ok {
+			return newKindErr(object.TYPE_ERROR, "Type mismatch: %s in %s, expected a STRING", left.Type(), right.Type())
+		}
+		return mapBool(strings.Contains(right.Value, sub.Value))
+
+	case *object.Hashmap:
+		key, ok := left.(object.Hashable)
+		if !ok {
+			return newKindErr(object.TYPE_ERROR, "Unusable as hashkey: %s", left.Type())
+		}
+		_, found := right.Get(key)
+		return mapBool(found)
+
+	default:
+		return newKindErr(object.TYPE_ERROR, "Unknown operator: %s in %s", left.Type(), right.Type())
+	}
+}
@@ -0,0 +1,271 @@
+/*
+String built-in functions :-
+
+Strings are UTF-8, and these work on runes (characters), not bytes : len("héllo") is 5, and so are the indices and widths.
+Strings are immutable, so they all return a new string. repeat and the paddings can't make a string over MAX_REPEAT_SIZE bytes.
+
+	split("a,b", ",")         // [a, b], without a separator it splits on whitespace, with "" it splits into characters
+	join(["a", 1], "-")       // a-1, the elements are joined with their display form
+	trim("  a  "), trim_left("xxa", "x"), trim_right("a\n") // whitespace, or the characters given
+	upper("a"), lower("A")
+	replace("aaa", "a", "b")  // bbb, replace("aaa", "a", "b", 1) only replaces the first one
+	starts_with("hello", "he"), ends_with("hello", "lo")
+	index_of("héllo", "l")    // 2, -1 if it's not there (index_of also works on lists)
+	repeat("ab", 2)           // abab
+	pad_left("7", 3, "0")     // 007, pad_right("a", 3) is "a  "
+	chars("hé")               // [h, é]
+	ord("é"), chr(233)        // 233, é
+*/
+package eval
+
+import (
+	"strings"
+	"trash/object"
+	"unicode/utf8"
+)
+
+func init() {
+	registerBuiltins(stringBuiltins)
+}
+
+func strArg(name string, arg object.Object) (string, *object.Error) {
+	str, ok := arg.(*object.String)
+	if !ok {
+		return "", newKindErr(object.TYPE_ERROR, `Builtin "%s" expected a STRING, got %s`, name, arg.Type())
+	}
+	return str.Value, nil
+}
+
+// all the args must be strings
+func strArgs(name string, args []object.Object) ([]string, *object.Error) {
+	strs := []string{}
+	for _, arg := range args {
+		str, err := strArg(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, str)
+	}
+	return strs, nil
+}
+
+func strList(strs []string) *object.List {
+	values := make([]object.Object, 0, len(strs))
+	for _, s := range strs {
+		values = append(values, &object.String{Value: s})
+	}
+	return &object.List{Values: values}
+}
+
+// the index in runes of sub in s, -1 if it's not there
+func stringIndexOf(s, sub string) int {
+	i := strings.Index(s, sub)
+	if i == -1 {
+		return -1
+	}
+	return utf8.RuneCountInString(s[:i])
+}
+
+// a string builtin that takes only strings and returns one
+func stringFunc(name string, nargs int, f func(strs []string) object.Object) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs(name, args, nargs, nargs); err != nil {
+				return err
+			}
+			strs, err := strArgs(name, args)
+			if err != nil {
+				return err
+			}
+			return f(strs)
+		},
+	}
+}
+
+func trimFunc(name string, trim func(s, cutset string) string) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs(name, args, 1, 2); err != nil {
+				return err
+			}
+			strs, err := strArgs(name, args)
+			if err != nil {
+				return err
+			}
+			cutset := " \t\n\r\v\f"
+			if len(strs) == 2 {
+				cutset = strs[1]
+			}
+			return &object.String{Value: trim(strs[0], cutset)}
+		},
+	}
+}
+
+func padFunc(name string, left bool) *object.Builtin {
+	return &object.Builtin{
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs(name, args, 2, 3); err != nil {
+				return err
+			}
+			s, err := strArg(name, args[0])
+			if err != nil {
+				return err
+			}
+			width, err := intArg(name, args[1])
+			if err != nil {
+				return err
+			}
+			pad := " "
+			if len(args) == 3 {
+				if pad, err = strArg(name, args[2]); err != nil {
+					return err
+				}
+				if utf8.RuneCountInString(pad) != 1 {
+					return newKindErr(object.ARG_ERROR, `Builtin "%s": the padding must be one character, got %q`, name, pad)
+				}
+			}
+			missing := width - int64(utf8.RuneCountInString(s))
+			if missing <= 0 {
+				return &object.String{Value: s}
+			}
+			if !repeatFits(int64(len(pad)), missing) {
+				return newKindErr(object.VALUE_ERROR, `Builtin "%s": the result would be over %d bytes`, name, MAX_REPEAT_SIZE)
+			}
+			if left {
+				return &object.String{Value: strings.Repeat(pad, int(missing)) + s}
+			}
+			return &object.String{Value: s + strings.Repeat(pad, int(missing))}
+		},
+	}
+}
+
+var stringBuiltins = map[string]*object.Builtin{
+	"split": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("split", args, 1, 2); err != nil {
+				return err
+			}
+			strs, err := strArgs("split", args)
+			if err != nil {
+				return err
+			}
+			if len(strs) == 1 {
+				return strList(strings.Fields(strs[0]))
+			}
+			return strList(strings.Split(strs[0], strs[1]))
+		},
+	},
+	"join": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("join", args, 1, 2); err != nil {
+				return err
+			}
+			list, err := listArg("join", args[0])
+			if err != nil {
+				return err
+			}
+			sep := ""
+			if len(args) == 2 {
+				if sep, err = strArg("join", args[1]); err != nil {
+					return err
+				}
+			}
+			parts := []string{}
+			for _, val := range list.Values {
+				parts = append(parts, val.Inspect())
+			}
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+	"trim":       trimFunc("trim", strings.Trim),
+	"trim_left":  trimFunc("trim_left", strings.TrimLeft),
+	"trim_right": trimFunc("trim_right", strings.TrimRight),
+	"upper": stringFunc("upper", 1, func(strs []string) object.Object {
+		return &object.String{Value: strings.ToUpper(strs[0])}
+	}),
+	"lower": stringFunc("lower", 1, func(strs []string) object.Object {
+		return &object.String{Value: strings.ToLower(strs[0])}
+	}),
+	"replace": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("replace", args, 3, 4); err != nil {
+				return err
+			}
+			strs, err := strArgs("replace", args[:3])
+			if err != nil {
+				return err
+			}
+			n := int64(-1)
+			if len(args) == 4 {
+				if n, err = intArg("replace", args[3]); err != nil {
+					return err
+				}
+			}
+			return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], int(n))}
+		},
+	},
+	"starts_with": stringFunc("starts_with", 2, func(strs []string) object.Object {
+		return mapBool(strings.HasPrefix(strs[0], strs[1]))
+	}),
+	"ends_with": stringFunc("ends_with", 2, func(strs []string) object.Object {
+		return mapBool(strings.HasSuffix(strs[0], strs[1]))
+	}),
+	"repeat": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("repeat", args, 2, 2); err != nil {
+				return err
+			}
+			s, err := strArg("repeat", args[0])
+			if err != nil {
+				return err
+			}
+			n, err := intArg("repeat", args[1])
+			if err != nil {
+				return err
+			}
+			if n < 0 {
+				n = 0
+			}
+			if !repeatFits(int64(len(s)), n) {
+				return newKindErr(object.VALUE_ERROR, `Builtin "repeat": the result would be over %d bytes`, MAX_REPEAT_SIZE)
+			}
+			return &object.String{Value: strings.Repeat(s, int(n))}
+		},
+	},
+	"pad_left":  padFunc("pad_left", true),
+	"pad_right": padFunc("pad_right", false),
+	"chars": stringFunc("chars", 1, func(strs []string) object.Object {
+		return strList(strings.Split(strs[0], ""))
+	}),
+	"ord": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("ord", args, 1, 1); err != nil {
+				return err
+			}
+			s, err := strArg("ord", args[0])
+			if err != nil {
+				return err
+			}
+			if utf8.RuneCountInString(s) != 1 {
+				return newKindErr(object.ARG_ERROR, `Builtin "ord" expected one character, got %q`, s)
+			}
+			r, _ := utf8.DecodeRuneInString(s)
+			return &object.Int{Value: int64(r)}
+		},
+	},
+	"chr": {
+		Func: func(ctx *object.Context, args ...object.Object) object.Object {
+			if err := checkArgs("chr", args, 1, 1); err != nil {
+				return err
+			}
+			code, err := intArg("chr", args[0])
+			if err != nil {
+				return err
+			}
+			if code < 0 || code > utf8.MaxRune || !utf8.ValidRune(rune(code)) {
+				return newKindErr(object.ARG_ERROR, `Builtin "chr": %d isn't a valid character code`, code)
+			}
+			return &object.String{Value: string(rune(code))}
+		},
+	},
+}
@@ -0,0 +1,120 @@
+/*
+try { ... } catch (e) { ... } finally { ... }
+
+Any error raised in the try block, thrown by the script or by the interpreter itself (type mismatches, unknown identifiers, ...),
+is caught and bound to e in a fresh env enclosed by the current one, as a hashmap :
+
+	{"message": "Identifier not found: x", "kind": "NameError", "stack": ["f(a)", "g()"], "data": null}
+
+The finally block always runs, after the try block (and the catch block if there was an error).
+An error or a return from the finally block overrides the result of the try/catch.
+
+	throw "boom"                         // Error: boom
+	throw error("bad input", {"line": 4}) // Error: bad input, with data
+	throw e                               // rethrow a caught error, keeping its kind and stack
+*/
+package eval
+
+import (
+	"trash/ast"
+	"trash/object"
+)
+
+func evalTryExpression(node *ast.TryExpression, env *object.Env) object.Object {
+	res := Eval(node.Block, env)
+
+	if err, ok := res.(*object.Error); ok && node.Catch != nil {
+		catchEnv := object.NewEnclosedEnv(env)
+		if node.Param != nil {
+			catchEnv.Set(node.Param.Value, errorToHash(err))
+		}
+		res = Eval(node.Catch, catchEnv)
+	}
+
+	if node.Finally != nil {
+		finally := Eval(node.Finally, env)
+		if finally != nil && (finally.Type() == object.ERROR_OBJ || finally.Type() == object.RETURN_OBJ) {
+			return finally
+		}
+	}
+
+	if res == nil {
+		return NULL
+	}
+	return res
+}
+
+func evalThrowStatement(node *ast.ThrowStatement, env *object.Env) object.Object {
+	val := Eval(node.Value, env)
+	if isErr(val) {
+		return val
+	}
+	return valueToError(val)
+}
+
+// the value a caught error is bound to
+func errorToHash(err *object.Error) *object.Hashmap {
+	kind := err.Kind
+	if kind == "" {
+		kind = object.ERROR
+	}
+	stack := []object.Object{}
+	for _, frame := range err.Stack {
+		stack = append(stack, &object.String{Value: frame})
+	}
+	var data object.Object = NULL
+	if err.Data != nil {
+		data = err.Data
+	}
+	return newErrorHash(err.Message, kind, &object.List{Values: stack}, data)
+}
+
+func newErrorHash(message, kind string, stack *object.List, data object.Object) *object.Hashmap {
+	hash := object.NewHashmap()
+	fields := []struct {
+		key   string
+		value object.Object
+	}{
+		{"message", &object.String{Value: message}},
+		{"kind", &object.String{Value: kind}},
+		{"stack", stack},
+		{"data", data},
+	}
+	for _, field := range fields {
+		hash.Set(&object.String{Value: field.key}, field.value)
+	}
+	return hash
+}
+
+// a thrown value : a string is the message, a hashmap with a message (an error(...) or a caught error) keeps its kind, stack and data,
+// anything else is the data of the error
+func valueToError(val object.Object) *object.Error {
+	switch val := val.(type) {
+	case *object.String:
+		return &object.Error{Message: val.Value, Kind: object.ERROR}
+	case *object.Hashmap:
+		message, ok := hashField(val, "message").(*object.String)
+		if !ok {
+			break
+		}
+		err := &object.Error{Message: message.Value, Kind: object.ERROR}
+		if kind, ok := hashField(val, "kind").(*object.String); ok {
+			err.Kind = kind.Value
+		}
+		if stack, ok := hashField(val, "stack").(*object.List); ok {
+			for _, frame := range stack.Values {
+				err.Stack = append(err.Stack, frame.Inspect())
+			}
+		}
+		if data := hashField(val, "data"); data != nil && data != NULL {
+			err.Data = data
+		}
+		return err
+	}
+	return &object.Error{Message: val.Inspect(), Kind: object.ERROR, Data: val}
+}
+
+func hashField(hash *object.Hashmap, name string) object.Object {
+	val, _ := hash.Get(&object.String{Value: name})
+	return val
+}
@@ -0,0 +1,165 @@
+/*
+A syntax highlighter for trash code, it uses the lexer so it colors the code the way the interpreter reads it :-
+
+	let greet = fn(name) { "Hello ${name}" }
+	^^^ keyword           ^^^^^^^^^^^^^^^^ string
+	    ^^^^^ identifier
+	          ^ operator
+
+The code is split into spans that cover all of it (spaces included), then written with ANSI colors or as HTML.
+Colors follow https://no-color.org : they're off when NO_COLOR is set and not empty.
+*/
+package highlight
+
+import (
+	"html"
+	"os"
+	"strings"
+	"trash/lexer"
+	"trash/token"
+)
+
+type Class int
+
+const (
+	Plain Class = iota // spaces and delimiters : ( ) { } [ ] , ;
+	Keyword
+	String
+	Number
+	Operator
+	Identifier
+	Illegal
+)
+
+// a piece of the code and how it's colored
+type Span struct {
+	Class Class
+	Text  string
+}
+
+// the ANSI colors of each class, Plain isn't colored
+var ansiColors = map[Class]string{
+	Keyword:    "\x1b[1;35m", // bold magenta
+	String:     "\x1b[32m",   // green
+	Number:     "\x1b[33m",   // yellow
+	Operator:   "\x1b[36m",   // cyan
+	Identifier: "\x1b[34m",   // blue
+	Illegal:    "\x1b[4;31m", // underlined red
+}
+
+const ansiReset = "\x1b[0m"
+
+// the css class of each class in the HTML, see CSS
+var htmlClasses = map[Class]string{
+	Keyword:    "kw",
+	String:     "str",
+	Number:     "num",
+	Operator:   "op",
+	Identifier: "id",
+	Illegal:    "err",
+}
+
+// a default style for the HTML
+const CSS = `pre.trash { background: #1e1e2e; color: #cdd6f4; padding: 1em; }
+pre.trash .kw { color: #cba6f7; font-weight: bold; }
+pre.trash .str { color: #a6e3a1; }
+pre.trash .num { color: #fab387; }
+pre.trash .op { color: #89dceb; }
+pre.trash .id { color: #89b4fa; }
+pre.trash .err { color: #f38ba8; text-decoration: underline wavy; }
+`
+
+var delimiters = map[token.TokenType]bool{
+	token.SEMICOLON:     true,
+	token.COMMA:         true,
+	token.LEFT_PAREN:    true,
+	token.RIGHT_PAREN:   true,
+	token.LEFT_BRACE:    true,
+	token.RIGHT_BRACE:   true,
+	token.LEFT_BRACKET:  true,
+	token.RIGHT_BRACKET: true,
+}
+
+func classify(tok token.Token) Class {
+	switch tok.Type {
+	case token.IDENT:
+		return Identifier
+	case token.INT:
+		return Number
+	case token.STRING, token.INTERP:
+		return String
+	case token.ILLEGAL:
+		return Illegal
+	}
+	if delimiters[tok.Type] {
+		return Plain
+	}
+	// the keywords are the words that aren't identifiers
+	if token.LookIdentifier(tok.Literal) == tok.Type {
+		return Keyword
+	}
+	return Operator
+}
+
+// split the code into spans, joining their Text gives back the code
+func Spans(code string) []Span {
+	spans := []Span{}
+	l := lexer.New(code)
+	tok := l.NextToken()
+	end := 0
+	for tok.Type != token.EOF {
+		next := l.NextToken()
+		if tok.Offset > end {
+			spans = append(spans, Span{Class: Plain, Text: code[end:tok.Offset]})
+		}
+		// a token goes until the next one, without the spaces the lexer skipped
+		end = len(strings.TrimRight(code[:next.Offset], " \t\n\r"))
+		if end < tok.Offset {
+			end = next.Offset
+		}
+		spans = append(spans, Span{Class: classify(tok), Text: code[tok.Offset:end]})
+		tok = next
+	}
+	if end < len(code) {
+		spans = append(spans, Span{Class: Plain, Text: code[end:]})
+	}
+	return spans
+}
+
+// a NO_COLOR that isn't empty turns off the colors, NO_COLOR="" keeps them
+func ColorEnabled() bool {
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// the code with ANSI colors
+func ANSI(code string) string {
+	var out strings.Builder
+	for _, span := range Spans(code) {
+		color, ok := ansiColors[span.Class]
+		if !ok {
+			out.WriteString(span.Text)
+			continue
+		}
+		out.WriteString(color)
+		out.WriteString(span.Text)
+		out.WriteString(ansiReset)
+	}
+	return out.String()
+}
+
+// the code as an HTML <pre>, the spans have the css classes of CSS
+func HTML(code string) string {
+	var out strings.Builder
+	out.WriteString(`<pre class="trash"><code>`)
+	for _, span := range Spans(code) {
+		text := html.EscapeString(span.Text)
+		class, ok := htmlClasses[span.Class]
+		if !ok {
+			out.WriteString(text)
+			continue
+		}
+		out.WriteString(`<span class="` + class + `">` + text + `</span>`)
+	}
+	out.WriteString("</code></pre>\n")
+	return out.String()
+}
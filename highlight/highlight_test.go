@@ -0,0 +1,118 @@
+package highlight
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpans(t *testing.T) {
+	code := "let x = fn(a) {\n  a + 0x1F; \"hi ${a}\" }  \n"
+	expected := []Span{
+		{Keyword, "let"}, {Plain, " "}, {Identifier, "x"}, {Plain, " "}, {Operator, "="}, {Plain, " "},
+		{Keyword, "fn"}, {Plain, "("}, {Identifier, "a"}, {Plain, ")"}, {Plain, " "}, {Plain, "{"}, {Plain, "\n  "},
+		{Identifier, "a"}, {Plain, " "}, {Operator, "+"}, {Plain, " "}, {Number, "0x1F"}, {Plain, ";"}, {Plain, " "},
+		{String, `"hi ${a}"`}, {Plain, " "}, {Plain, "}"}, {Plain, "  \n"},
+	}
+	spans := Spans(code)
+	if len(spans) != len(expected) {
+		t.Fatalf("wrong number of spans. expected=%d, got=%d: %v", len(expected), len(spans), spans)
+	}
+	for i, span := range spans {
+		if span != expected[i] {
+			t.Errorf("spans[%d]: expected=%v, got=%v", i, expected[i], span)
+		}
+	}
+}
+
+func TestSpansCoverTheCode(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"true is false; x in [1, ...rest]",
+		"`raw\n string` \"esc\\\"aped\" café << 2",
+		"\"unterminated  ",
+		"let @ = \"\\q\"",
+	}
+	for _, code := range tests {
+		joined := ""
+		for _, span := range Spans(code) {
+			joined += span.Text
+		}
+		if joined != code {
+			t.Errorf("the spans don't cover the code. expected=%q, got=%q", code, joined)
+		}
+	}
+}
+
+func TestClasses(t *testing.T) {
+	tests := []struct {
+		code  string
+		class Class
+	}{
+		{"match", Keyword},
+		{"true", Keyword},
+		{"in", Keyword},
+		{"print", Identifier},
+		{"λ", Identifier},
+		{"1_000", Number},
+		{"`raw`", String},
+		{"=>", Operator},
+		{"...", Operator},
+		{"<<", Operator},
+		{".", Operator},
+		{";", Plain},
+		{"@", Illegal},
+		{`"open`, Illegal},
+	}
+	for _, tt := range tests {
+		spans := Spans(tt.code)
+		if len(spans) != 1 || spans[0].Class != tt.class {
+			t.Errorf("%q: expected one span of class %d, got %v", tt.code, tt.class, spans)
+		}
+	}
+}
+
+func TestANSI(t *testing.T) {
+	got := ANSI("let x = 1;")
+	expected := "\x1b[1;35mlet\x1b[0m \x1b[34mx\x1b[0m \x1b[36m=\x1b[0m \x1b[33m1\x1b[0m;"
+	if got != expected {
+		t.Errorf("expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := HTML(`x < "<b>"`)
+	expected := `<pre class="trash"><code><span class="id">x</span> <span class="op">&lt;</span> <span class="str">&#34;&lt;b&gt;&#34;</span></code></pre>` + "\n"
+	if got != expected {
+		t.Errorf("expected=%q, got=%q", expected, got)
+	}
+	for _, class := range htmlClasses {
+		if !strings.Contains(CSS, "."+class+" ") {
+			t.Errorf("no style for the class %q", class)
+		}
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		value   string
+		enabled bool
+	}{
+		{"", true},
+		{"1", false},
+		{"false", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("NO_COLOR", tt.value)
+		if ColorEnabled() != tt.enabled {
+			t.Errorf("NO_COLOR=%q: expected the colors enabled=%t", tt.value, tt.enabled)
+		}
+	}
+
+	// t.Setenv restores it after the test
+	os.Unsetenv("NO_COLOR")
+	if !ColorEnabled() {
+		t.Errorf("NO_COLOR isn't set, the colors should be on")
+	}
+}
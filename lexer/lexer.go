@@ -1,86 +1,290 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"trash/token"
+	"unicode"
+	"unicode/utf8"
 )
 
+// the input is read as UTF-8, one rune at a time. The positions are byte offsets in the input
 type Lexer struct {
 	input        string
 	position     int  // current position in the input file.
 	nextPosition int  // current reading position in input
-	ch           byte // the current position char
+	ch           rune // the current position char
+	width        int  // the width in bytes of the current char, an invalid UTF-8 byte is a utf8.RuneError of width 1
+	line         int  // the line of the current char, starting at 1
+	column       int  // the column of the current char in runes, starting at 1
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 // give us the next character and advance our position in the input string
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
 	// reset the current position character to "NUL"
 	if l.nextPosition >= len(l.input) {
-		l.ch = 0
+		l.ch, l.width = 0, 0
 	} else {
-		l.ch = l.input[l.nextPosition]
+		l.ch, l.width = utf8.DecodeRuneInString(l.input[l.nextPosition:])
 	}
 	l.position = l.nextPosition
-	l.nextPosition++
+	l.nextPosition += l.width
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// the current char isn't valid UTF-8 (a real U+FFFD in the input is 3 bytes wide)
+func (l *Lexer) invalidChar() bool {
+	return l.ch == utf8.RuneError && l.width == 1
+}
+
+func (l *Lexer) invalidCharMsg() string {
+	return fmt.Sprintf("invalid UTF-8 byte %#x at line %d, column %d", l.input[l.position], l.line, l.column)
+}
+
+// the characters after a \ in a string
+var escapes = map[rune]string{
+	'n':  "\n",
+	't':  "\t",
+	'r':  "\r",
+	'0':  "\x00",
+	'\\': "\\",
+	'"':  "\"",
+	'\'': "'",
+	'$':  "$",
+}
+
+// read a "..." string, handling the escapes : \n \t \r \0 \\ \" \' \$ and \u{1F600}
+// if the string is unterminated or has an invalid escape, the last return value is the error.
+// a string with ${...} in it is interpolated, it's not unescaped here, the parser splits it with SplitInterpolated
+func (l *Lexer) readString() (string, bool, string) {
+	var out strings.Builder
+	start := l.position + 1
+	startLine := l.line
+	interpolated := false
+	errMsg := ""
 	for {
 		l.readChar()
 
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch l.ch {
+		case 0:
+			return "", false, fmt.Sprintf("unterminated string starting at line %d", startLine)
+		case '"':
+			if interpolated {
+				return l.input[start:l.position], true, errMsg
+			}
+			return out.String(), false, errMsg
+		case '$':
+			if l.readAhead() != '{' {
+				out.WriteRune(l.ch)
+				continue
+			}
+			l.readChar()
+			interpolated = true
+			if msg := l.skipInterpolation(); msg != "" {
+				return "", false, msg
+			}
+		case '\\':
+			l.readChar()
+			if escaped, ok := escapes[l.ch]; ok {
+				out.WriteString(escaped)
+			} else if l.ch == 'u' {
+				r, ok := l.readUnicodeEscape()
+				if !ok && errMsg == "" {
+					errMsg = fmt.Sprintf("invalid unicode escape in string at line %d, expected \\u{...} with 1 to 6 hex digits", l.line)
+				}
+				out.WriteRune(r)
+			} else if l.ch == 0 {
+				return "", false, fmt.Sprintf("unterminated string starting at line %d", startLine)
+			} else if errMsg == "" {
+				errMsg = fmt.Sprintf("unknown escape sequence \\%c in string at line %d", l.ch, l.line)
+			}
+		default:
+			if l.invalidChar() && errMsg == "" {
+				errMsg = l.invalidCharMsg()
+			}
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// skip the expression of a ${...}, the current char is the {. It can have braces and strings in it : "${f({"a": "}"})}"
+func (l *Lexer) skipInterpolation() string {
+	startLine := l.line
+	depth := 1
+	for depth > 0 {
+		l.readChar()
+		switch l.ch {
+		case 0:
+			return fmt.Sprintf("unterminated ${ in string at line %d", startLine)
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if _, _, msg := l.readString(); msg != "" {
+				return msg
+			}
+		case '`':
+			if _, msg := l.readRawString(); msg != "" {
+				return msg
+			}
+		}
+	}
+	return ""
+}
+
+// SplitInterpolated splits the body of an interpolated string (an INTERP token) into its literal parts, unescaped,
+// and the source of its ${...} expressions : "a${x}b${y}" gives [a, b, ""] and [x, y], there's always one more literal.
+func SplitInterpolated(raw string, line int) ([]string, []string, string) {
+	literals, exprs := []string{}, []string{}
+	var chunk strings.Builder
+	unescape := func() string {
+		l := New(`"` + chunk.String() + `"`)
+		l.line = line
+		str, _, msg := l.readString()
+		chunk.Reset()
+		literals = append(literals, str)
+		return msg
+	}
+
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case raw[i] == '\\' && i+1 < len(raw):
+			chunk.WriteString(raw[i : i+2])
+			i++
+		case raw[i] == '$' && i+1 < len(raw) && raw[i+1] == '{':
+			if msg := unescape(); msg != "" {
+				return nil, nil, msg
+			}
+			// the lexer already checked the braces are balanced
+			l := New(raw[i+1:])
+			l.skipInterpolation()
+			exprs = append(exprs, raw[i+2:i+1+l.position])
+			i += 1 + l.position
+		default:
+			chunk.WriteByte(raw[i])
+		}
+	}
+	if msg := unescape(); msg != "" {
+		return nil, nil, msg
+	}
+	return literals, exprs, ""
+}
+
+// the {...} of \u{...}, the current char is the u
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	if l.readAhead() != '{' {
+		return utf8.RuneError, false
+	}
+	l.readChar()
+	start := l.nextPosition
+	for l.readAhead() != '}' && l.readAhead() != '"' && l.readAhead() != 0 {
+		l.readChar()
+	}
+	digits := l.input[start:l.nextPosition]
+	if l.readAhead() != '}' {
+		return utf8.RuneError, false
+	}
+	l.readChar()
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		return utf8.RuneError, false
+	}
+	return rune(code), true
+}
+
+// read a `...` raw string : no escapes, and it can span multiple lines
+func (l *Lexer) readRawString() (string, string) {
+	startLine := l.line
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '`' {
+			return l.input[position:l.position], ""
+		}
+		if l.ch == 0 {
+			return "", fmt.Sprintf("unterminated raw string starting at line %d", startLine)
+		}
+		if l.invalidChar() {
+			return "", l.invalidCharMsg()
 		}
 	}
-	return l.input[position:l.position]
 }
 
-// check if the character is a word
-// SUPPORT : ASCII only for now
-func isLetter(ch byte) bool {
+// a string token, or an ILLEGAL token with the error as its literal
+func stringToken(str, errMsg string) token.Token {
+	if errMsg != "" {
+		return token.Token{Type: token.ILLEGAL, Literal: errMsg}
+	}
+	return token.Token{Type: token.STRING, Literal: str}
+}
+
+// the raw body of an interpolated string is kept as the literal of an INTERP token
+func (l *Lexer) readStringToken() token.Token {
+	str, interpolated, errMsg := l.readString()
+	if interpolated && errMsg == "" {
+		return token.Token{Type: token.INTERP, Literal: str}
+	}
+	return stringToken(str, errMsg)
+}
+
+// check if the character can start an identifier : any unicode letter or _, so café and λ are identifiers
+func isLetter(ch rune) bool {
 	if 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' {
 		return true
 	}
-	return false
+	return ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+// check if the character can continue an identifier : a letter, a digit of any script, or a combining mark (the accent of a decomposed é)
+func isIdentChar(ch rune) bool {
+	return isLetter(ch) || isDigit(ch) || ch >= utf8.RuneSelf && (unicode.IsDigit(ch) || unicode.In(ch, unicode.Mn, unicode.Mc))
 }
 
-// check if the character is a digit
-func isDigit(ch byte) bool {
+// check if the character is a digit, numbers are ASCII only
+func isDigit(ch rune) bool {
 	if '0' <= ch && ch <= '9' {
 		return true
 	}
 	return false
 }
 
+// read a number : 42, 1_000, 0x1F, 0o755, 0b1010. The letters right after it are read too (0x1G, 12abc),
+// so the parser can report the whole literal as malformed instead of an INT followed by an IDENT
 func (l *Lexer) readInt() string {
 	startPos := l.position
-	for isDigit(l.ch) {
+	for isDigit(l.ch) || isLetter(l.ch) {
 		l.readChar()
 	}
 	return l.input[startPos:l.position]
 }
 
-func (l *Lexer) readAhead() byte {
-	if l.nextPosition > len(l.input) {
+func (l *Lexer) readAhead() rune {
+	if l.nextPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.nextPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.nextPosition:])
+	return ch
 }
 
 // read a complete word until the end, and update the position & nextPosition
-// SUPPORT : ASCII only for now
 func (l *Lexer) readIdentifer() string {
 
 	// read until you find a " "
 	startPos := l.position
-	// can read letters with digits inside i think
-	for isLetter(l.ch) || isDigit(l.ch) {
+	for isIdentChar(l.ch) {
 		l.readChar()
 	}
 	return l.input[startPos:l.position]
@@ -93,66 +297,128 @@ func (l *Lexer) skipSpaces() {
 	}
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(ch),
 	}
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var t token.Token
+// make a token out of the current and the next character : ==, +=, ++ ...
+func (l *Lexer) newTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{
+		Type:    tokenType,
+		Literal: string(ch) + string(l.ch),
+	}
+}
 
+func (l *Lexer) NextToken() token.Token {
 	// skip spaces
 	l.skipSpaces()
 
+	line, column, offset := l.line, l.column, l.position
+	t := l.readToken()
+	t.Line, t.Column, t.Offset = line, column, offset
+	return t
+}
+
+func (l *Lexer) readToken() token.Token {
+	var t token.Token
+
 	switch l.ch {
 	// operators
-	// TODO: refactor these branches
 	case '=':
-		if l.readAhead() == '=' {
-			ch := l.ch
-			l.readChar()
-			t = token.Token{
-				Type:    token.EQUAL,
-				Literal: string(ch) + string(l.ch),
-			}
-		} else {
+		switch l.readAhead() {
+		case '=':
+			t = l.newTwoCharToken(token.EQUAL)
+		case '>':
+			t = l.newTwoCharToken(token.ARROW)
+		default:
 			t = newToken(token.ASSIGN, l.ch)
 		}
 	case '!':
 		if l.readAhead() == '=' {
-			ch := l.ch
-			l.readChar()
-			t = token.Token{
-				Type:    token.NOT_EQUAL,
-				Literal: string(ch) + string(l.ch),
-			}
+			t = l.newTwoCharToken(token.NOT_EQUAL)
 		} else {
 			t = newToken(token.BANG, l.ch)
 		}
 	case '"':
-		t.Type = token.STRING
-		t.Literal = l.readString()
+		t = l.readStringToken()
+	case '`':
+		t = stringToken(l.readRawString())
 	case '+':
-		t = newToken(token.PLUS, l.ch)
+		switch l.readAhead() {
+		case '=':
+			t = l.newTwoCharToken(token.PLUS_ASSIGN)
+		case '+':
+			t = l.newTwoCharToken(token.INCREMENT)
+		default:
+			t = newToken(token.PLUS, l.ch)
+		}
 	case ':':
 		t = newToken(token.COLON, l.ch)
 	case '-':
-		t = newToken(token.NEG, l.ch)
+		switch l.readAhead() {
+		case '=':
+			t = l.newTwoCharToken(token.NEG_ASSIGN)
+		case '-':
+			t = l.newTwoCharToken(token.DECREMENT)
+		default:
+			t = newToken(token.NEG, l.ch)
+		}
 	case '*':
-		t = newToken(token.MUL, l.ch)
+		if l.readAhead() == '=' {
+			t = l.newTwoCharToken(token.MUL_ASSIGN)
+		} else {
+			t = newToken(token.MUL, l.ch)
+		}
 	case '/':
-		t = newToken(token.DIV, l.ch)
+		if l.readAhead() == '=' {
+			t = l.newTwoCharToken(token.DIV_ASSIGN)
+		} else {
+			t = newToken(token.DIV, l.ch)
+		}
+	case '%':
+		if l.readAhead() == '=' {
+			t = l.newTwoCharToken(token.MOD_ASSIGN)
+		} else {
+			t = newToken(token.MOD, l.ch)
+		}
 	case '>':
-		t = newToken(token.GT, l.ch)
+		if l.readAhead() == '>' {
+			t = l.newTwoCharToken(token.SHIFT_RIGHT)
+		} else {
+			t = newToken(token.GT, l.ch)
+		}
 	case '<':
-		t = newToken(token.LT, l.ch)
+		if l.readAhead() == '<' {
+			t = l.newTwoCharToken(token.SHIFT_LEFT)
+		} else {
+			t = newToken(token.LT, l.ch)
+		}
+	case '&':
+		t = newToken(token.BIT_AND, l.ch)
+	case '|':
+		t = newToken(token.BIT_OR, l.ch)
+	case '^':
+		t = newToken(token.BIT_XOR, l.ch)
+	case '~':
+		t = newToken(token.BIT_NOT, l.ch)
 	// delimiters
 	case ';':
 		t = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		t = newToken(token.COMMA, l.ch)
+	case '.':
+		if strings.HasPrefix(l.input[l.position:], token.ELLIPSIS) {
+			l.readChar()
+			l.readChar()
+			t = token.Token{Type: token.ELLIPSIS, Literal: token.ELLIPSIS}
+		} else {
+			t = newToken(token.DOT, l.ch)
+		}
 	case '(':
 		t = newToken(token.LEFT_PAREN, l.ch)
 	case ')':
@@ -182,8 +448,13 @@ func (l *Lexer) NextToken() token.Token {
 			t.Literal = l.readInt()
 			t.Type = token.INT
 			return t
+		} else if l.invalidChar() {
+			t.Type = token.ILLEGAL
+			t.Literal = l.invalidCharMsg()
 		} else {
-			t = newToken(token.ILLEGAL, l.ch)
+			// the literal of an ILLEGAL token is the error, the parser reports it as is
+			t.Type = token.ILLEGAL
+			t.Literal = fmt.Sprintf("unexpected character %q at line %d, column %d", l.ch, l.line, l.column)
 		}
 	}
 
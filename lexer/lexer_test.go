@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"testing"
 	"trash/token"
 )
@@ -25,6 +26,15 @@ func TestNextToken(t *testing.T) {
 		"this is a string"
 		[1,2,3]
 		{"foo": "bar"}
+		obj.name
+		x += 1; x -= 1; x *= 2; x /= 2; x %= 2; x++; --x; 5 % 2
+		[a, ...rest]
+		match (x) { _ => 1 }
+		try { throw e } catch (e) {} finally {}
+		a is b
+		a in b
+		0x1F 1_000 0b1010 0x1G
+		a & b | c ^ ~d << 1 >> 2 < >
 	`
 	expectedTests := []struct {
 		expectedType    token.TokenType
@@ -114,6 +124,93 @@ func TestNextToken(t *testing.T) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RIGHT_BRACE, "}"},
+		{token.IDENT, "obj"},
+		{token.DOT, "."},
+		{token.IDENT, "name"},
+
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.NEG_ASSIGN, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MUL_ASSIGN, "*="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.DIV_ASSIGN, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MOD_ASSIGN, "%="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.INCREMENT, "++"},
+		{token.SEMICOLON, ";"},
+		{token.DECREMENT, "--"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "5"},
+		{token.MOD, "%"},
+		{token.INT, "2"},
+		{token.LEFT_BRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RIGHT_BRACKET, "]"},
+		{token.MATCH, "match"},
+		{token.LEFT_PAREN, "("},
+		{token.IDENT, "x"},
+		{token.RIGHT_PAREN, ")"},
+		{token.LEFT_BRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RIGHT_BRACE, "}"},
+		{token.TRY, "try"},
+		{token.LEFT_BRACE, "{"},
+		{token.THROW, "throw"},
+		{token.IDENT, "e"},
+		{token.RIGHT_BRACE, "}"},
+		{token.CATCH, "catch"},
+		{token.LEFT_PAREN, "("},
+		{token.IDENT, "e"},
+		{token.RIGHT_PAREN, ")"},
+		{token.LEFT_BRACE, "{"},
+		{token.RIGHT_BRACE, "}"},
+		{token.FINALLY, "finally"},
+		{token.LEFT_BRACE, "{"},
+		{token.RIGHT_BRACE, "}"},
+		{token.IDENT, "a"},
+		{token.IS, "is"},
+		{token.IDENT, "b"},
+		{token.IDENT, "a"},
+		{token.IN, "in"},
+		{token.IDENT, "b"},
+		{token.INT, "0x1F"},
+		{token.INT, "1_000"},
+		{token.INT, "0b1010"},
+		{token.INT, "0x1G"},
+
+		{token.IDENT, "a"},
+		{token.BIT_AND, "&"},
+		{token.IDENT, "b"},
+		{token.BIT_OR, "|"},
+		{token.IDENT, "c"},
+		{token.BIT_XOR, "^"},
+		{token.BIT_NOT, "~"},
+		{token.IDENT, "d"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.INT, "1"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.INT, "2"},
+		{token.LT, "<"},
+		{token.GT, ">"},
 
 		{token.EOF, ""},
 	}
@@ -130,3 +227,144 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\nb"`, token.STRING, "a\nb"},
+		{`"tab\there"`, token.STRING, "tab\there"},
+		{`"\r\0"`, token.STRING, "\r\x00"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"it\'s"`, token.STRING, "it's"},
+		{`"\u{48}\u{e9}\u{1F600}"`, token.STRING, "Hé😀"},
+		{`"héllo"`, token.STRING, "héllo"},
+		{"`raw \\n \"string\"`", token.STRING, `raw \n "string"`},
+		{"`multi\nline`", token.STRING, "multi\nline"},
+		{"``", token.STRING, ""},
+		{`"\q"`, token.ILLEGAL, `unknown escape sequence \q in string at line 1`},
+		{`"\u{}"`, token.ILLEGAL, `invalid unicode escape in string at line 1, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u{110000}"`, token.ILLEGAL, `invalid unicode escape in string at line 1, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u{zz}"`, token.ILLEGAL, `invalid unicode escape in string at line 1, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u41"`, token.ILLEGAL, `invalid unicode escape in string at line 1, expected \u{...} with 1 to 6 hex digits`},
+		{"\n\n\"never closed; let x = 1;", token.ILLEGAL, "unterminated string starting at line 3"},
+		{`"ends with escape\`, token.ILLEGAL, "unterminated string starting at line 1"},
+		{"\n`raw", token.ILLEGAL, "unterminated raw string starting at line 2"},
+		{"\n  @", token.ILLEGAL, "unexpected character '@' at line 2, column 3"},
+		{"€", token.ILLEGAL, "unexpected character '€' at line 1, column 1"},
+		{"\"ab\xffc\"", token.ILLEGAL, "invalid UTF-8 byte 0xff at line 1, column 4"},
+		{"`\xc3`", token.ILLEGAL, "invalid UTF-8 byte 0xc3 at line 1, column 2"},
+	}
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("%q: wrong token type. expected=%q, got=%q", tt.input, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("%q: wrong literal. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenLines(t *testing.T) {
+	input := "let x = 1;\n\nlet s = `a\nb`;\nx"
+	expected := []struct {
+		literal string
+		line    int
+	}{
+		{"let", 1}, {"x", 1}, {"=", 1}, {"1", 1}, {";", 1},
+		{"let", 3}, {"s", 3}, {"=", 3}, {"a\nb", 3}, {";", 4},
+		{"x", 5},
+	}
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Literal != tt.literal || tok.Line != tt.line {
+			t.Errorf("tests[%d]: expected %q at line %d, got %q at line %d", i, tt.literal, tt.line, tok.Literal, tok.Line)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let café = λ + ñ2;\nπ_r² \xff résumé x\u0301 日本"
+	expected := []struct {
+		tokenType token.TokenType
+		literal   string
+		line      int
+		column    int
+	}{
+		{token.LET, "let", 1, 1},
+		{token.IDENT, "café", 1, 5},
+		{token.ASSIGN, "=", 1, 10},
+		{token.IDENT, "λ", 1, 12},
+		{token.PLUS, "+", 1, 14},
+		{token.IDENT, "ñ2", 1, 16},
+		{token.SEMICOLON, ";", 1, 18},
+		// ² isn't a letter nor a decimal digit
+		{token.IDENT, "π_r", 2, 1},
+		{token.ILLEGAL, "unexpected character '²' at line 2, column 4", 2, 4},
+		{token.ILLEGAL, "invalid UTF-8 byte 0xff at line 2, column 6", 2, 6},
+		{token.IDENT, "résumé", 2, 8},
+		// a combining accent continues an identifier
+		{token.IDENT, "x\u0301", 2, 15},
+		{token.IDENT, "日本", 2, 18},
+		{token.EOF, "", 2, 20},
+	}
+	l := New(input)
+	for i, tt := range expected {
+		tok := l.NextToken()
+		if tok.Type != tt.tokenType || tok.Literal != tt.literal {
+			t.Fatalf("tests[%d]: expected %s %q, got %s %q", i, tt.tokenType, tt.literal, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Errorf("tests[%d]: %q expected at %d:%d, got %d:%d", i, tt.literal, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedLiterals []string
+		expectedExprs    []string
+	}{
+		{`"Hello ${name}!"`, token.INTERP, "Hello ${name}!", []string{"Hello ", "!"}, []string{"name"}},
+		{`"${a}${b}"`, token.INTERP, "${a}${b}", []string{"", "", ""}, []string{"a", "b"}},
+		{`"tab\t${x}\n"`, token.INTERP, `tab\t${x}\n`, []string{"tab\t", "\n"}, []string{"x"}},
+		{`"${f({"a": "}"})} done"`, token.INTERP, `${f({"a": "}"})} done`, []string{"", " done"}, []string{`f({"a": "}"})`}},
+		{`"${"inner ${x}"}"`, token.INTERP, `${"inner ${x}"}`, []string{"", ""}, []string{`"inner ${x}"`}},
+		{`"cost: \${x} $5"`, token.STRING, "cost: ${x} $5", nil, nil},
+		{`"${x`, token.ILLEGAL, "unterminated ${ in string at line 1", nil, nil},
+		// the quote starts a string inside the ${
+		{`"${x"`, token.ILLEGAL, "unterminated string starting at line 1", nil, nil},
+		{`"${x} \q"`, token.ILLEGAL, `unknown escape sequence \q in string at line 1`, nil, nil},
+	}
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("%s: expected %s %q, got %s %q", tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+			continue
+		}
+		if tok.Type != token.INTERP {
+			continue
+		}
+		literals, exprs, msg := SplitInterpolated(tok.Literal, tok.Line)
+		if msg != "" {
+			t.Errorf("%s: unexpected error %q", tt.input, msg)
+			continue
+		}
+		if strings.Join(literals, "|") != strings.Join(tt.expectedLiterals, "|") || len(literals) != len(tt.expectedLiterals) {
+			t.Errorf("%s: wrong literals. expected=%q, got=%q", tt.input, tt.expectedLiterals, literals)
+		}
+		if strings.Join(exprs, "|") != strings.Join(tt.expectedExprs, "|") {
+			t.Errorf("%s: wrong expressions. expected=%q, got=%q", tt.input, tt.expectedExprs, exprs)
+		}
+	}
+}
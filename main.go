@@ -2,19 +2,29 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
+	"trash/highlight"
 	"trash/repl"
 )
 
 const INTER_NAME = "Trash"
 
 func main() {
-	args := os.Args
+	// out of range indices and missing keys are errors instead of NULL, same as "use strict"
+	strict := flag.Bool("strict", false, "raise errors on out of range indices and missing keys")
+	flag.Parse()
+	args := flag.Args()
 
-	if len(args) == 2 {
-		filePath := args[1]
+	if len(args) > 0 && args[0] == "highlight" {
+		os.Exit(highlightCommand(args[1:]))
+	}
+
+	if len(args) == 1 {
+		filePath := args[0]
 		// Reading from a file
 		file, err := os.Open(filePath)
 		if err != nil {
@@ -23,8 +33,8 @@ func main() {
 		}
 		defer file.Close()
 
-		repl.StartWithFile(bufio.NewReader(file), os.Stdout)
-	} else if len(args) == 1 {
+		repl.StartWithFile(bufio.NewReader(file), os.Stdout, *strict)
+	} else if len(args) == 0 {
 
 		user, err := user.Current()
 		if err != nil {
@@ -32,6 +42,57 @@ func main() {
 		}
 		fmt.Printf("Hi %s!, Ever heard of %s ?\n", user.Username, INTER_NAME)
 		fmt.Printf("Type something in %s\n", INTER_NAME)
-		repl.Start(os.Stdin, os.Stdout)
+		repl.Start(os.Stdin, os.Stdout, *strict)
+	}
+}
+
+// trash highlight file.tsh --format=ansi|html, prints the file highlighted (stdin without a file).
+// The ANSI colors are left out when NO_COLOR is set and not empty
+func highlightCommand(args []string) int {
+	cmd := flag.NewFlagSet("highlight", flag.ContinueOnError)
+	format := cmd.String("format", "ansi", "the output format: ansi or html")
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), "Usage: trash highlight [file.tsh] [--format=ansi|html]")
+		cmd.PrintDefaults()
+	}
+
+	// the flags can come before or after the file
+	files := []string{}
+	for {
+		if err := cmd.Parse(args); err != nil {
+			return 2
+		}
+		if cmd.NArg() == 0 {
+			break
+		}
+		files = append(files, cmd.Arg(0))
+		args = cmd.Args()[1:]
+	}
+	if len(files) > 1 || *format != "ansi" && *format != "html" {
+		cmd.Usage()
+		return 2
+	}
+
+	var code []byte
+	var err error
+	if len(files) == 0 {
+		code, err = io.ReadAll(os.Stdin)
+	} else {
+		code, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return 1
+	}
+
+	switch {
+	case *format == "html":
+		fmt.Printf("<style>\n%s</style>\n", highlight.CSS)
+		fmt.Print(highlight.HTML(string(code)))
+	case highlight.ColorEnabled():
+		fmt.Print(highlight.ANSI(string(code)))
+	default:
+		fmt.Print(string(code))
 	}
+	return 0
 }
@@ -37,10 +37,13 @@ aka : Scopes
 */
 package object
 
+import "sort"
+
 // --- Environment : used to keep track of assigned objects (basically a hashmap)
 type Env struct {
-	store map[string]Object
-	outer *Env
+	store  map[string]Object
+	outer  *Env
+	strict bool // out of range indices and missing keys are errors instead of NULL
 }
 
 func NewEnv() *Env {
@@ -65,6 +68,30 @@ func (env *Env) Set(key string, val Object) {
 	env.store[key] = val
 }
 
+// strict mode is turned on with the "use strict" pragma or the --strict flag, enclosed envs inherit it
+func (env *Env) SetStrict(strict bool) {
+	env.strict = strict
+}
+
+func (env *Env) Strict() bool {
+	for e := env; e != nil; e = e.outer {
+		if e.strict {
+			return true
+		}
+	}
+	return false
+}
+
+// the names bound in this env (not the outer ones), sorted
+func (env *Env) Names() []string {
+	names := make([]string, 0, len(env.store))
+	for name := range env.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func NewEnclosedEnv(outerEnv *Env) *Env {
 	env := NewEnv()
 	env.outer = outerEnv
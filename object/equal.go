@@ -0,0 +1,87 @@
+/*
+Equality used by == and != :-
+
+  - ints, strings and bools are equal if they have the same value
+  - null is only equal to null
+  - lists are equal if they have the same length and their elements are equal one by one
+  - hashmaps are equal if they have the same keys, and the values of each key are equal (the order of the keys doesn't matter)
+  - functions and builtins are only equal to themselves
+  - values of different types are never equal, there is no conversion : 1 == "1" is false
+    (unlike < and >, which are only defined for ints, and error on anything else)
+
+Lists and hashmaps can contain themselves (xs[0] = xs), so the pairs being compared are remembered,
+and comparing a pair again while it's already being compared is taken as equal instead of looping forever.
+
+The `is` operator is for identity : lists, hashmaps and functions are the same only if they're the same value in memory,
+ints, strings, bools and null are immutable so they're compared by value.
+*/
+package object
+
+func Equal(a, b Object) bool {
+	return equal(a, b, map[[2]Object]bool{})
+}
+
+func equal(a, b Object, seen map[[2]Object]bool) bool {
+	if a == b {
+		return true
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Int, *String, *Bool:
+		return keysEqual(a, b)
+
+	case *Null:
+		return true
+
+	case *List:
+		b := b.(*List)
+		if len(a.Values) != len(b.Values) {
+			return false
+		}
+		pair := [2]Object{a, b}
+		if seen[pair] {
+			return true
+		}
+		seen[pair] = true
+		for i := range a.Values {
+			if !equal(a.Values[i], b.Values[i], seen) {
+				return false
+			}
+		}
+		return true
+
+	case *Hashmap:
+		b := b.(*Hashmap)
+		if a.Len() != b.Len() {
+			return false
+		}
+		pair := [2]Object{a, b}
+		if seen[pair] {
+			return true
+		}
+		seen[pair] = true
+		for _, p := range a.Pairs() {
+			value, ok := b.Get(p.Key.(Hashable))
+			if !ok || !equal(p.Value, value, seen) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// the `is` operator
+func Identical(a, b Object) bool {
+	switch a.(type) {
+	case *Int, *String, *Bool, *Null:
+		return a.Type() == b.Type() && equal(a, b, nil)
+	default:
+		return a == b
+	}
+}
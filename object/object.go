@@ -36,6 +36,9 @@ type Int struct {
 }
 type String struct {
 	Value string
+
+	hash   uint64 // cached by HashKey(), strings are never mutated after they're created
+	hashed bool
 }
 type Bool struct {
 	Value bool
@@ -47,10 +50,19 @@ type ReturnValue struct {
 }
 type Error struct {
 	Message string
+	Kind    string   // one of the *_ERROR kinds below, "Error" if thrown by the user without a kind
+	Data    Object   // extra payload given to error(msg, data) or throw, nil if none
+	Stack   []string // function names the error passed through, innermost first
+}
+type BuiltinFuncs func(ctx *Context, args ...Object) Object
+
+// given to the builtins on every call, so they can call back into the interpreter (map, filter, sort, ...)
+type Context struct {
+	Call func(fn Object, args ...Object) Object // calls a user function or a builtin, errors are returned as *Error
 }
-type BuiltinFuncs func(args ...Object) Object
 
 type Builtin struct {
+	Name string // set when it's registered, shown in the stack of errors raised in its callbacks
 	Func BuiltinFuncs
 }
 type List struct {
@@ -76,7 +88,8 @@ type List struct {
 	// => (key1 == key2)=false
 
 Hasing keys : Strings, Booleans, Integers
-requirements: hashing the key must be unique, no two keys should have equal hashes.
+requirements: equal keys must have equal hashes. Two different strings can still have the same FNV hash (a collision),
+so the hashmap compares the keys themselves too, the colliding pairs are kept in an overflow list.
 */
 
 type HashKey struct {
@@ -86,7 +99,8 @@ type HashKey struct {
 
 // used in the eval to check if the object is a usable as Hashkey when evaluating hash literals or indexing keys in hashmap.
 type Hashable interface {
-	HashKey() HashKey // TODO: Performance - Cache the return values
+	Object
+	HashKey() HashKey
 }
 
 func (b *Bool) HashKey() HashKey {
@@ -100,10 +114,13 @@ func (b *Bool) HashKey() HashKey {
 }
 
 func (s *String) HashKey() HashKey {
-	hash := fnv.New64a()
-	hash.Write([]byte(s.Value))
-
-	return HashKey{Type: s.Type(), Value: hash.Sum64()}
+	if !s.hashed {
+		hash := fnv.New64a()
+		hash.Write([]byte(s.Value))
+		s.hash = hash.Sum64()
+		s.hashed = true
+	}
+	return HashKey{Type: s.Type(), Value: s.hash}
 }
 
 func (i *Int) HashKey() HashKey {
@@ -117,6 +134,129 @@ type HashPair struct {
 
 type Hashmap struct {
 	Store map[HashKey]HashPair // to be able to print the key and value when Inspect(), also if later implementing something like iter (range)
+
+	overflow map[HashKey][]HashPair // pairs whose keys have the same hash as the key in Store but aren't equal to it
+	keys     []Hashable             // insertion order of the keys, so printing and iterating are deterministic
+}
+
+func NewHashmap() *Hashmap {
+	return &Hashmap{Store: make(map[HashKey]HashPair), overflow: make(map[HashKey][]HashPair)}
+}
+
+// same hash isn't enough, the keys must have the same value too
+func keysEqual(a, b Object) bool {
+	switch a := a.(type) {
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Int:
+		b, ok := b.(*Int)
+		return ok && a.Value == b.Value
+	case *Bool:
+		b, ok := b.(*Bool)
+		return ok && a.Value == b.Value
+	}
+	return false
+}
+
+func (hm *Hashmap) Get(key Hashable) (Object, bool) {
+	hashed := key.HashKey()
+	pair, ok := hm.Store[hashed]
+	if !ok {
+		return nil, false
+	}
+	if keysEqual(pair.Key, key) {
+		return pair.Value, true
+	}
+	for _, pair := range hm.overflow[hashed] {
+		if keysEqual(pair.Key, key) {
+			return pair.Value, true
+		}
+	}
+	return nil, false
+}
+
+// updating an existing key keeps its position
+func (hm *Hashmap) Set(key Hashable, value Object) {
+	hashed := key.HashKey()
+	pair, ok := hm.Store[hashed]
+	if !ok {
+		hm.Store[hashed] = HashPair{Key: key, Value: value}
+		hm.keys = append(hm.keys, key)
+		return
+	}
+	if keysEqual(pair.Key, key) {
+		hm.Store[hashed] = HashPair{Key: pair.Key, Value: value}
+		return
+	}
+	chain := hm.overflow[hashed]
+	for i, pair := range chain {
+		if keysEqual(pair.Key, key) {
+			chain[i].Value = value
+			return
+		}
+	}
+	hm.overflow[hashed] = append(chain, HashPair{Key: key, Value: value})
+	hm.keys = append(hm.keys, key)
+}
+
+func (hm *Hashmap) Delete(key Hashable) bool {
+	hashed := key.HashKey()
+	pair, ok := hm.Store[hashed]
+	if !ok {
+		return false
+	}
+	chain := hm.overflow[hashed]
+	if keysEqual(pair.Key, key) {
+		// the first colliding pair takes its place
+		if len(chain) == 0 {
+			delete(hm.Store, hashed)
+		} else {
+			hm.Store[hashed] = chain[0]
+			hm.setOverflow(hashed, chain[1:])
+		}
+	} else {
+		found := false
+		for i, pair := range chain {
+			if keysEqual(pair.Key, key) {
+				hm.setOverflow(hashed, append(chain[:i:i], chain[i+1:]...))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, k := range hm.keys {
+		if keysEqual(k, key) {
+			hm.keys = append(hm.keys[:i], hm.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (hm *Hashmap) setOverflow(hashed HashKey, chain []HashPair) {
+	if len(chain) == 0 {
+		delete(hm.overflow, hashed)
+		return
+	}
+	hm.overflow[hashed] = chain
+}
+
+func (hm *Hashmap) Len() int {
+	return len(hm.keys)
+}
+
+// the pairs in insertion order
+func (hm *Hashmap) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, len(hm.keys))
+	for _, k := range hm.keys {
+		value, _ := hm.Get(k)
+		pairs = append(pairs, HashPair{Key: k, Value: value})
+	}
+	return pairs
 }
 
 const (
@@ -132,37 +272,39 @@ const (
 	HASHMAP_OBJ = "HASH"
 )
 
+// error kinds, exposed to the scripts as the "kind" field of a caught error
+const (
+	ERROR          = "Error"
+	TYPE_ERROR     = "TypeError"
+	NAME_ERROR     = "NameError"
+	ARG_ERROR      = "ArgumentError"
+	ZERO_DIV_ERROR = "ZeroDivisionError"
+	MATCH_ERROR    = "MatchError"
+	INDEX_ERROR    = "IndexError"
+	KEY_ERROR      = "KeyError"
+	VALUE_ERROR    = "ValueError"
+)
+
 // --- Hashmap
 func (hm *Hashmap) Type() ObjectType {
 	return HASHMAP_OBJ
 }
+
+// a hashmap that contains itself is printed as {...}
 func (hm *Hashmap) Inspect() string {
-	var out bytes.Buffer
-	pairs := []string{}
-	for _, pair := range hm.Store {
-		pairs = append(pairs, fmt.Sprintf("%s: %s",
-			pair.Key.Inspect(), pair.Value.Inspect()))
-	}
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
-	return out.String()
+	p := &printer{path: map[Object]bool{}}
+	return p.print(hm)
 }
 
 // --- List
 func (ls *List) Type() ObjectType {
 	return LIST_OBJ
 }
+
+// a list that contains itself is printed as [...]
 func (ls *List) Inspect() string {
-	var out bytes.Buffer
-	elements := []string{}
-	for _, e := range ls.Values {
-		elements = append(elements, e.Inspect())
-	}
-	out.WriteString("[")
-	out.WriteString(strings.Join(elements, ", "))
-	out.WriteString("]")
-	return out.String()
+	p := &printer{path: map[Object]bool{}}
+	return p.print(ls)
 }
 
 // --- Builtin functions
@@ -200,7 +342,7 @@ func (b *Bool) Type() ObjectType {
 // --- Null
 // Tony Hoare's “billion-dollar mistake”.
 func (n *Null) Inspect() string {
-	return "Null"
+	return "null"
 }
 func (n *Null) Type() ObjectType {
 	return NULL_OBJ
@@ -216,7 +358,16 @@ func (r *ReturnValue) Type() ObjectType {
 
 // --- Error
 func (e *Error) Inspect() string {
-	return "Error: " + e.Message
+	kind := e.Kind
+	if kind == "" {
+		kind = ERROR
+	}
+	var out bytes.Buffer
+	out.WriteString(kind + ": " + e.Message)
+	for _, frame := range e.Stack {
+		out.WriteString("\n    in " + frame)
+	}
+	return out.String()
 }
 func (e *Error) Type() ObjectType {
 	return ERROR_OBJ
@@ -224,11 +375,34 @@ func (e *Error) Type() ObjectType {
 
 // the reason I am putting Env here to allow direct access of the Environment where function is defined in, this is useful for adding closures
 type Function struct {
-	Params []*ast.Identifier
+	Name   string
+	Params []ast.Expression
+	Rest   *ast.Identifier
 	Body   *ast.BlockStatement
 	Env    *Env
 }
 
+// name(a, b = 10, ...rest), used in the errors of calling the function
+func (f *Function) Signature() string {
+	var out bytes.Buffer
+	params := []string{}
+	for _, p := range f.Params {
+		params = append(params, p.String())
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
+	if f.Name != "" {
+		out.WriteString(f.Name)
+	} else {
+		out.WriteString("fn")
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(")")
+	return out.String()
+}
+
 func (f *Function) Type() ObjectType {
 	return FUNC_OBJ
 }
@@ -238,6 +412,9 @@ func (f *Function) Inspect() string {
 	for _, p := range f.Params {
 		params = append(params, p.String())
 	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
 	out.WriteString("fn")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
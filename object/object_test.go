@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"trash/ast"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -17,3 +21,221 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestHashmapOrder(t *testing.T) {
+	hash := NewHashmap()
+	hash.Set(&String{Value: "b"}, &Int{Value: 1})
+	hash.Set(&Int{Value: 2}, &Int{Value: 2})
+	hash.Set(&String{Value: "a"}, &Int{Value: 3})
+	hash.Set(&String{Value: "b"}, &Int{Value: 4}) // updating keeps the position
+
+	if hash.Inspect() != "{b: 4, 2: 2, a: 3}" {
+		t.Errorf("wrong Inspect. got=%q", hash.Inspect())
+	}
+
+	if !hash.Delete(&Int{Value: 2}) {
+		t.Errorf("expected key 2 to be deleted")
+	}
+	if hash.Delete(&Int{Value: 2}) {
+		t.Errorf("expected key 2 to be already deleted")
+	}
+	hash.Set(&Int{Value: 2}, &Int{Value: 5})
+	if hash.Inspect() != "{b: 4, a: 3, 2: 5}" {
+		t.Errorf("wrong Inspect. got=%q", hash.Inspect())
+	}
+	if hash.Len() != 3 {
+		t.Errorf("wrong Len. got=%d", hash.Len())
+	}
+	if val, ok := hash.Get(&String{Value: "a"}); !ok || val.Inspect() != "3" {
+		t.Errorf("wrong value for a. got=%v", val)
+	}
+}
+
+// two strings with the same hash (a collision) must not overwrite each other
+func TestHashmapCollisions(t *testing.T) {
+	colliding := func(value string) *String {
+		return &String{Value: value, hash: 42, hashed: true}
+	}
+	hash := NewHashmap()
+	hash.Set(colliding("a"), &Int{Value: 1})
+	hash.Set(colliding("b"), &Int{Value: 2})
+	hash.Set(colliding("c"), &Int{Value: 3})
+	hash.Set(colliding("b"), &Int{Value: 4})
+
+	if hash.Len() != 3 {
+		t.Fatalf("wrong Len. got=%d", hash.Len())
+	}
+	if hash.Inspect() != "{a: 1, b: 4, c: 3}" {
+		t.Errorf("wrong Inspect. got=%q", hash.Inspect())
+	}
+	if _, ok := hash.Get(colliding("d")); ok {
+		t.Errorf("expected no value for d")
+	}
+
+	if !hash.Delete(colliding("a")) {
+		t.Errorf("expected a to be deleted")
+	}
+	if hash.Delete(colliding("d")) {
+		t.Errorf("expected d not to be found")
+	}
+	for key, expected := range map[string]string{"b": "4", "c": "3"} {
+		val, ok := hash.Get(colliding(key))
+		if !ok || val.Inspect() != expected {
+			t.Errorf("wrong value for %s. got=%v", key, val)
+		}
+	}
+	if !hash.Delete(colliding("c")) || hash.Inspect() != "{b: 4}" {
+		t.Errorf("wrong Inspect after delete. got=%q", hash.Inspect())
+	}
+}
+
+func TestStringHashKeyCached(t *testing.T) {
+	s := &String{Value: "cached"}
+	key := s.HashKey()
+	if !s.hashed || s.hash != key.Value {
+		t.Errorf("hash key isn't cached")
+	}
+	if s.HashKey() != key {
+		t.Errorf("cached hash key differs")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	one := func() Object { return &Int{Value: 1} }
+	a := &List{Values: []Object{one(), nil}}
+	a.Values[1] = a
+	b := &List{Values: []Object{one(), nil}}
+	b.Values[1] = b
+	if !Equal(a, b) {
+		t.Errorf("expected cyclic lists to be equal")
+	}
+
+	h1, h2 := NewHashmap(), NewHashmap()
+	h1.Set(&String{Value: "x"}, a)
+	h2.Set(&String{Value: "x"}, b)
+	if !Equal(h1, h2) {
+		t.Errorf("expected hashmaps with cyclic lists to be equal")
+	}
+	h2.Set(&String{Value: "y"}, one())
+	if Equal(h1, h2) {
+		t.Errorf("expected hashmaps with different keys not to be equal")
+	}
+
+	if Equal(one(), &String{Value: "1"}) {
+		t.Errorf("expected 1 and \"1\" not to be equal")
+	}
+	if Identical(a, b) || !Identical(a, a) || !Identical(one(), one()) {
+		t.Errorf("wrong identity")
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	outer := NewEnv()
+	outer.Set("b", &Int{Value: 1})
+	outer.Set("a", &Int{Value: 2})
+	inner := NewEnclosedEnv(outer)
+	inner.Set("c", &Int{Value: 3})
+
+	if names := outer.Names(); strings.Join(names, ",") != "a,b" {
+		t.Errorf("wrong outer names: %v", names)
+	}
+	if names := inner.Names(); strings.Join(names, ",") != "c" {
+		t.Errorf("wrong inner names: %v", names)
+	}
+}
+
+func TestRepr(t *testing.T) {
+	hash := NewHashmap()
+	hash.Set(&String{Value: "a"}, &List{Values: []Object{&Int{Value: 1}, &String{Value: "1"}}})
+	hash.Set(&Int{Value: 2}, &Null{})
+	cyclic := &List{Values: []Object{&Int{Value: 1}}}
+	cyclic.Values = append(cyclic.Values, cyclic)
+	selfHash := NewHashmap()
+	selfHash.Set(&String{Value: "self"}, selfHash)
+
+	tests := []struct {
+		obj     Object
+		repr    string
+		inspect string
+	}{
+		{&String{Value: "hi"}, `"hi"`, "hi"},
+		{&String{Value: "say \"hi\"\n\t\\ ${x} $5 \x00\x07é"}, `"say \"hi\"\n\t\\ \${x} $5 \0\u{7}é"`, "say \"hi\"\n\t\\ ${x} $5 \x00\x07é"},
+		{hash, `{"a": [1, "1"], 2: null}`, "{a: [1, 1], 2: null}"},
+		{&Function{Name: "add", Params: []ast.Expression{&ast.Identifier{Value: "a"}, &ast.Identifier{Value: "b"}}}, "<fn add(a, b)>", ""},
+		{&Function{Params: []ast.Expression{}, Rest: &ast.Identifier{Value: "rest"}}, "<fn(...rest)>", ""},
+		{&Builtin{Name: "len"}, "<builtin len>", "Built-in Function"},
+		{cyclic, "[1, [...]]", "[1, [...]]"},
+		{selfHash, `{"self": {...}}`, "{self: {...}}"},
+		{&List{Values: []Object{cyclic, cyclic}}, "[[1, [...]], [1, [...]]]", "[[1, [...]], [1, [...]]]"},
+	}
+	for _, tt := range tests {
+		if got := Repr(tt.obj); got != tt.repr {
+			t.Errorf("wrong repr. expected=%q, got=%q", tt.repr, got)
+		}
+		if tt.inspect == "" {
+			continue
+		}
+		if got := tt.obj.Inspect(); got != tt.inspect {
+			t.Errorf("wrong inspect. expected=%q, got=%q", tt.inspect, got)
+		}
+	}
+}
+
+func TestPretty(t *testing.T) {
+	ints := func(values ...int64) *List {
+		list := &List{Values: []Object{}}
+		for _, v := range values {
+			list.Values = append(list.Values, &Int{Value: v})
+		}
+		return list
+	}
+	nested := NewHashmap()
+	nested.Set(&String{Value: "short"}, ints(1, 2))
+	nested.Set(&String{Value: "long"}, &List{Values: []Object{ints(100, 200, 300), ints(400, 500, 600), ints()}})
+	cyclic := ints(1, 2, 3)
+	cyclic.Values = append(cyclic.Values, cyclic)
+
+	tests := []struct {
+		obj      Object
+		width    int
+		expected string
+	}{
+		{ints(1, 2, 3), 80, "[1, 2, 3]"},
+		{ints(1, 2, 3), 8, "[\n  1,\n  2,\n  3\n]"},
+		{nested, 80, `{"short": [1, 2], "long": [[100, 200, 300], [400, 500, 600], []]}`},
+		{nested, 24, `{
+  "short": [1, 2],
+  "long": [
+    [100, 200, 300],
+    [400, 500, 600],
+    []
+  ]
+}`},
+		{nested, 10, `{
+  "short": [
+    1,
+    2
+  ],
+  "long": [
+    [
+      100,
+      200,
+      300
+    ],
+    [
+      400,
+      500,
+      600
+    ],
+    []
+  ]
+}`},
+		{cyclic, 10, "[\n  1,\n  2,\n  3,\n  [...]\n]"},
+		{&String{Value: "a string wider than the width"}, 5, `"a string wider than the width"`},
+	}
+	for _, tt := range tests {
+		if got := Pretty(tt.obj, tt.width); got != tt.expected {
+			t.Errorf("width %d: expected=\n%s\ngot=\n%s", tt.width, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/*
+The repr of a value is close to how it's written in code, so the REPL can show what a value really is :-
+
+	Inspect : [1, 1, a]             fn(x) {\n(x * 2)\n}
+	Repr    : [1, "1", "a"]         <fn double(x)>
+
+Strings are quoted (with the escapes of the lexer), functions are <fn name(a, b)>, builtins are <builtin len>, and
+a list or a hashmap that contains itself is printed as [...] or {...}.
+
+Pretty is the repr wrapped to a width : a list or a hashmap that doesn't fit on its line gets one element per line, indented.
+
+	{
+	  "name": "trash",
+	  "tags": ["lang", "interpreter"],
+	  "versions": [
+	    {"id": 1, "date": "2024-01-01"},
+	    {"id": 2, "date": "2024-06-01"}
+	  ]
+	}
+*/
+package object
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// the indentation of the nested elements in Pretty
+const PRETTY_INDENT = "  "
+
+func Repr(obj Object) string {
+	p := &printer{repr: true, path: map[Object]bool{}}
+	return p.print(obj)
+}
+
+// the repr, with the lists and hashmaps that are wider than width broken over multiple lines
+func Pretty(obj Object, width int) string {
+	p := &printer{repr: true, path: map[Object]bool{}}
+	return p.pretty(obj, "", 0, width)
+}
+
+// quote a string the way it's written in code : "say \"hi\"\n"
+func Quote(s string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == 0:
+			out.WriteString(`\0`)
+		case r == '$' && strings.HasPrefix(s[i+1:], "{"):
+			// not an interpolation
+			out.WriteString(`\$`)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&out, `\u{%X}`, r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
+// prints values, Inspect uses it too (with repr off) so it doesn't loop forever on a list that contains itself
+type printer struct {
+	repr bool            // quote the strings, <fn name(a, b)> for the functions
+	path map[Object]bool // the lists and hashmaps being printed, seeing one again is a cycle
+}
+
+func (p *printer) print(obj Object) string {
+	switch obj := obj.(type) {
+	case *String:
+		if p.repr {
+			return Quote(obj.Value)
+		}
+		return obj.Value
+	case *List:
+		if p.path[obj] {
+			return "[...]"
+		}
+		p.path[obj] = true
+		defer delete(p.path, obj)
+		elements := make([]string, 0, len(obj.Values))
+		for _, val := range obj.Values {
+			elements = append(elements, p.print(val))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Hashmap:
+		if p.path[obj] {
+			return "{...}"
+		}
+		p.path[obj] = true
+		defer delete(p.path, obj)
+		pairs := make([]string, 0, obj.Len())
+		for _, pair := range obj.Pairs() {
+			pairs = append(pairs, p.print(pair.Key)+": "+p.print(pair.Value))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case *ReturnValue:
+		return p.print(obj.Value)
+	}
+	if !p.repr {
+		return obj.Inspect()
+	}
+	switch obj := obj.(type) {
+	case *Function:
+		if obj.Name == "" {
+			return "<" + obj.Signature() + ">"
+		}
+		return "<fn " + obj.Signature() + ">"
+	case *Builtin:
+		return "<builtin " + obj.Name + ">"
+	}
+	return obj.Inspect()
+}
+
+// the value printed at the column `used` of a line (after the indent and a hashmap key), breaking what doesn't fit in width
+func (p *printer) pretty(obj Object, indent string, used, width int) string {
+	flat := p.print(obj)
+	if used+utf8.RuneCountInString(flat) <= width {
+		return flat
+	}
+
+	inner := indent + PRETTY_INDENT
+	lines := []string{}
+	var open, close string
+	switch obj := obj.(type) {
+	case *List:
+		if len(obj.Values) == 0 || p.path[obj] {
+			return flat
+		}
+		p.path[obj] = true
+		defer delete(p.path, obj)
+		open, close = "[", "]"
+		for _, val := range obj.Values {
+			// the +1 is the comma
+			lines = append(lines, inner+p.pretty(val, inner, utf8.RuneCountInString(inner)+1, width))
+		}
+	case *Hashmap:
+		if obj.Len() == 0 || p.path[obj] {
+			return flat
+		}
+		p.path[obj] = true
+		defer delete(p.path, obj)
+		open, close = "{", "}"
+		for _, pair := range obj.Pairs() {
+			key := p.print(pair.Key) + ": "
+			used := utf8.RuneCountInString(inner+key) + 1
+			lines = append(lines, inner+key+p.pretty(pair.Value, inner, used, width))
+		}
+	default:
+		return flat
+	}
+	return open + "\n" + strings.Join(lines, ",\n") + "\n" + indent + close
+}
@@ -10,6 +10,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"trash/ast"
 	"trash/lexer"
 	"trash/token"
@@ -25,30 +26,54 @@ type (
 What we want out of these constants is to later be able to answer:
 - Does the * operator have a higher precedence than the == operator?
 - Does a prefx operator have a higher preference than a call expression?
+
+The bitwise operators follow C, they're lower than ==, so compare with parentheses : (flags & MASK) == MASK
 */
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // x = y, right associative
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
 	EQUALS      // ==
 	LESSGREATER // < >
+	SHIFT       // << >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -x or !x
-	CALL        // myfunc(x)
+	CALL        // myfunc(x) or x++
 	INDEX       // list[index]: the highest precedence
 )
 
 var precedences = map[token.TokenType]int{
+	token.ASSIGN:       ASSIGN,
+	token.PLUS_ASSIGN:  ASSIGN,
+	token.NEG_ASSIGN:   ASSIGN,
+	token.MUL_ASSIGN:   ASSIGN,
+	token.DIV_ASSIGN:   ASSIGN,
+	token.MOD_ASSIGN:   ASSIGN,
 	token.MUL:          PRODUCT,
 	token.DIV:          PRODUCT,
+	token.MOD:          PRODUCT,
 	token.EQUAL:        EQUALS,
 	token.NOT_EQUAL:    EQUALS,
+	token.IS:           EQUALS,
+	token.IN:           LESSGREATER,
 	token.GT:           LESSGREATER,
 	token.LT:           LESSGREATER,
+	token.BIT_OR:       BIT_OR,
+	token.BIT_XOR:      BIT_XOR,
+	token.BIT_AND:      BIT_AND,
+	token.SHIFT_LEFT:   SHIFT,
+	token.SHIFT_RIGHT:  SHIFT,
 	token.PLUS:         SUM,
 	token.NEG:          SUM,
 	token.LEFT_PAREN:   CALL,
+	token.INCREMENT:    CALL,
+	token.DECREMENT:    CALL,
 	token.LEFT_BRACKET: INDEX,
+	token.DOT:          INDEX,
 }
 
 type Parser struct {
@@ -73,12 +98,17 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.INTERP, p.parseInterpolatedString)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.NEG, p.parsePrefixExpression)
+	p.registerPrefix(token.BIT_NOT, p.parsePrefixExpression)
+	p.registerPrefix(token.INCREMENT, p.parsePrefixUpdateExpression)
+	p.registerPrefix(token.DECREMENT, p.parsePrefixUpdateExpression)
 	p.registerPrefix(token.TRUE, p.parseBooleanExpression)
 	p.registerPrefix(token.FALSE, p.parseBooleanExpression)
 	p.registerPrefix(token.LEFT_BRACKET, p.parseListLiteral)
 	p.registerPrefix(token.LEFT_BRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
 
 	// infix
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -87,12 +117,27 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerInfix(token.NEG, p.parseInfixExpression)
 	p.registerInfix(token.MUL, p.parseInfixExpression)
 	p.registerInfix(token.DIV, p.parseInfixExpression)
+	p.registerInfix(token.MOD, p.parseInfixExpression)
+	p.registerInfix(token.IS, p.parseInfixExpression)
+	for _, tok := range []token.TokenType{token.BIT_AND, token.BIT_OR, token.BIT_XOR, token.SHIFT_LEFT, token.SHIFT_RIGHT} {
+		p.registerInfix(tok, p.parseInfixExpression)
+	}
+	p.registerInfix(token.IN, p.parseInfixExpression)
 	p.registerInfix(token.EQUAL, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQUAL, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression) // special one
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.NEG_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MUL_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.DIV_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MOD_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixUpdateExpression)
 	p.registerInfix(token.LEFT_PAREN, p.parseCallExpression)    // special one
 	p.registerInfix(token.LEFT_BRACKET, p.parseIndexExpression) // special one
+	p.registerInfix(token.DOT, p.parseMemberExpression)         // special one
 
 	// grouped
 	// we only need to parse the left pren !!!
@@ -101,6 +146,9 @@ func New(lexer *lexer.Lexer) *Parser {
 	// other keywords if, else... etc
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNC, p.parseFunctionLiteral)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 
 	// read 2 tokens so current and next token are set
 	p.nextToken()
@@ -154,6 +202,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -166,6 +216,12 @@ func (p *Parser) getPrecedence(tokenType token.TokenType) int {
 	return LOWEST
 }
 
+// the lexer puts the error in the literal of ILLEGAL tokens : unterminated strings, unexpected characters, ...
+func (p *Parser) parseIllegal() ast.Expression {
+	p.errors = append(p.errors, p.currToken.Literal)
+	return nil
+}
+
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("No prefix parse function for %s found", t)
 	p.errors = append(p.errors, msg)
@@ -198,54 +254,79 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	id := &ast.Identifier{
+	return &ast.Identifier{
 		Token: p.currToken,
 		Value: p.currToken.Literal,
 	}
+}
 
-	if p.TokenIs(p.peekToken, token.ASSIGN) {
-		return p.parseAssignIdentifier(id)
+// check if the expression can be on the left side of an =
+func isAssignable(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+		return true
+	default:
+		return false
 	}
-	return id
 }
 
-func (p *Parser) parseAssignIdentifier(id *ast.Identifier) ast.Expression {
+func (p *Parser) checkAssignable(target ast.Expression) bool {
+	// the left side already failed to parse
+	if target == nil {
+		return false
+	}
+	if !isAssignable(target) {
+		msg := fmt.Sprintf("invalid assignment target: %s", target)
+		p.errors = append(p.errors, msg)
+		return false
+	}
+	return true
+}
 
-	// x = x + 30
+// x = x + 30, a[i][j] = 1, obj.field.sub = 2, x += 1
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
 	ae := &ast.AssignExpression{
-		Token: p.currToken,
-		Name:  id,
+		Token:    p.currToken,
+		Target:   target,
+		Operator: p.currToken.Literal,
 	}
 
-	if !p.expectNextToken(token.ASSIGN) {
+	if !p.checkAssignable(target) {
 		return nil
 	}
 
 	// advance after the assign =
 	p.nextToken()
 
-	ae.Value = p.parseExpression(LOWEST)
-
-	if p.TokenIs(p.peekToken, token.SEMICOLON) {
-		p.nextToken()
-	}
+	// a = b = c is a = (b = c), so the right side is parsed with a lower precedence
+	ae.Value = p.parseExpression(ASSIGN - 1)
 
 	return ae
 }
 
 // let <identifier> = <expression>
+// let <pattern> = <expression>
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{
 		Token: p.currToken,
 	}
 
-	if !p.expectNextToken(token.IDENT) {
-		return nil
-	}
-	// the left side
-	stmt.Name = &ast.Identifier{
-		Token: p.currToken,
-		Value: p.currToken.Literal,
+	switch p.peekToken.Type {
+	case token.LEFT_BRACKET, token.LEFT_BRACE:
+		p.nextToken()
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	default:
+		if !p.expectNextToken(token.IDENT) {
+			return nil
+		}
+		// the left side
+		stmt.Name = &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
 	}
 
 	if !p.expectNextToken(token.ASSIGN) {
@@ -255,6 +336,11 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// let add = fn(a, b) {...}, the function gets the name add
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok && stmt.Name != nil {
+		fl.Name = stmt.Name.Value
+	}
+
 	for p.TokenIs(p.peekToken, token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -289,6 +375,24 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	}
 	return stmt
 }
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if p.TokenIs(p.peekToken, token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{
 		Token: p.currToken,
@@ -307,11 +411,9 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 		Token: p.currToken,
 	}
 
-	intValue, err := strconv.ParseInt(p.currToken.Literal, 0, 64)
-
-	if err != nil {
-		msg := fmt.Sprintf("Couldn't parse %s as integer", p.currToken.Literal)
-		p.errors = append(p.errors, msg)
+	intValue, errMsg := parseInt(p.currToken.Literal)
+	if errMsg != "" {
+		p.errors = append(p.errors, fmt.Sprintf("invalid integer literal %s: %s", p.currToken.Literal, errMsg))
 		return nil
 	}
 
@@ -320,6 +422,51 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+// the integer literal prefixes and their base
+var intBases = map[string]struct {
+	base   int
+	name   string
+	digits string
+}{
+	"0x": {16, "hex", "0123456789abcdefABCDEF"},
+	"0o": {8, "octal", "01234567"},
+	"0b": {2, "binary", "01"},
+}
+
+// 42, 1_000_000, 0x1F, 0o755, 0b1010 : underscores can only be between two digits, and decimals can't have leading zeros
+func parseInt(literal string) (int64, string) {
+	digits, base, name, valid := literal, 10, "decimal", "0123456789"
+	if len(literal) >= 2 {
+		if prefix, ok := intBases[strings.ToLower(literal[:2])]; ok {
+			digits, base, name, valid = literal[2:], prefix.base, prefix.name, prefix.digits
+			if digits == "" {
+				return 0, fmt.Sprintf("expected %s digits after %s", name, literal[:2])
+			}
+		}
+	}
+
+	for i, ch := range digits {
+		if ch == '_' {
+			if i == 0 || i == len(digits)-1 || digits[i-1] == '_' {
+				return 0, "underscores can only be used between digits"
+			}
+			continue
+		}
+		if !strings.ContainsRune(valid, ch) {
+			return 0, fmt.Sprintf("%q isn't a valid %s digit", ch, name)
+		}
+	}
+	if base == 10 && len(digits) > 1 && digits[0] == '0' {
+		return 0, "leading zeros aren't allowed, use 0o for octal"
+	}
+
+	value, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+	if err != nil {
+		return 0, "out of range for a 64 bit integer"
+	}
+	return value, ""
+}
+
 // parse inside a list of expression : take an end : ])
 func (p *Parser) parseListExpression(endToken token.TokenType) []ast.Expression {
 	res := []ast.Expression{}
@@ -364,20 +511,95 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	p.nextToken()
 
 	// the index should be the next token parsed
-	ind.Index = p.parseExpression(LOWEST)
+	var start ast.Expression
+	if !p.TokenIs(p.currToken, token.COLON) {
+		start = p.parseExpression(LOWEST)
+		if !p.TokenIs(p.peekToken, token.COLON) {
+			if !p.expectNextToken(token.RIGHT_BRACKET) {
+				return nil
+			}
+			ind.Index = start
+			return ind
+		}
+		p.nextToken()
+	}
 
-	if !p.expectNextToken(token.RIGHT_BRACKET) {
-		return nil
+	return p.parseSliceExpression(ind.Token, left, start)
+}
+
+// xs[start:end:step], the current token is the first :
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	slice := &ast.SliceExpression{
+		Token: tok,
+		Left:  left,
+		Start: start,
 	}
 
-	if p.TokenIs(p.peekToken, token.ASSIGN) {
+	if !p.TokenIs(p.peekToken, token.COLON) && !p.TokenIs(p.peekToken, token.RIGHT_BRACKET) {
 		p.nextToken()
+		slice.End = p.parseExpression(LOWEST)
+	}
+	if p.TokenIs(p.peekToken, token.COLON) {
 		p.nextToken()
+		if !p.TokenIs(p.peekToken, token.RIGHT_BRACKET) {
+			p.nextToken()
+			slice.Step = p.parseExpression(LOWEST)
+		}
+	}
 
-		ind.Value = p.parseExpression(LOWEST)
+	if !p.expectNextToken(token.RIGHT_BRACKET) {
+		return nil
 	}
+	return slice
+}
 
-	return ind
+// ++x or --x
+func (p *Parser) parsePrefixUpdateExpression() ast.Expression {
+	ue := &ast.UpdateExpression{
+		Token:    p.currToken,
+		Operator: p.currToken.Literal,
+		Prefix:   true,
+	}
+
+	p.nextToken()
+
+	ue.Target = p.parseExpression(PREFIX)
+	if !p.checkAssignable(ue.Target) {
+		return nil
+	}
+	return ue
+}
+
+// x++ or x--
+func (p *Parser) parsePostfixUpdateExpression(target ast.Expression) ast.Expression {
+	ue := &ast.UpdateExpression{
+		Token:    p.currToken,
+		Operator: p.currToken.Literal,
+		Target:   target,
+	}
+
+	if !p.checkAssignable(target) {
+		return nil
+	}
+	return ue
+}
+
+// obj.name
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	me := &ast.MemberExpression{
+		Token:  p.currToken,
+		Object: left,
+	}
+
+	if !p.expectNextToken(token.IDENT) {
+		return nil
+	}
+	me.Property = &ast.Identifier{
+		Token: p.currToken,
+		Value: p.currToken.Literal,
+	}
+
+	return me
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
@@ -387,6 +609,43 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	}
 	return lit
 }
+
+// each ${...} is parsed on its own, with a new parser, its errors are reported like the others
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	is := &ast.InterpolatedString{
+		Token: p.currToken,
+	}
+	literals, sources, errMsg := lexer.SplitInterpolated(p.currToken.Literal, p.currToken.Line)
+	if errMsg != "" {
+		p.errors = append(p.errors, errMsg)
+		return nil
+	}
+
+	for i, literal := range literals {
+		if literal != "" {
+			is.Parts = append(is.Parts, &ast.StringLiteral{Token: token.Token{Type: token.STRING, Literal: literal, Line: p.currToken.Line}, Value: literal})
+		}
+		if i == len(sources) {
+			break
+		}
+		if strings.TrimSpace(sources[i]) == "" {
+			p.errors = append(p.errors, fmt.Sprintf("empty ${} in string at line %d", p.currToken.Line))
+			return nil
+		}
+		sub := New(lexer.New(sources[i]))
+		exp := sub.parseExpression(LOWEST)
+		if !sub.TokenIs(sub.peekToken, token.EOF) {
+			sub.errors = append(sub.errors, fmt.Sprintf("expected } after the expression in ${%s}, got %s instead", sources[i], sub.peekToken.Literal))
+		}
+		if len(sub.errors) != 0 {
+			p.errors = append(p.errors, sub.errors...)
+			return nil
+		}
+		is.Parts = append(is.Parts, exp)
+	}
+	return is
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	pe := &ast.PrefixExpression{
 		Token:    p.currToken,
@@ -468,6 +727,53 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{
+		Token: p.currToken,
+	}
+
+	if !p.expectNextToken(token.LEFT_BRACE) {
+		return nil
+	}
+	exp.Block = p.parseBlockStatement()
+
+	if p.TokenIs(p.peekToken, token.CATCH) {
+		p.nextToken()
+
+		// the binding is optional : catch { ... }
+		if p.TokenIs(p.peekToken, token.LEFT_PAREN) {
+			p.nextToken()
+			if !p.expectNextToken(token.IDENT) {
+				return nil
+			}
+			exp.Param = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			if !p.expectNextToken(token.RIGHT_PAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectNextToken(token.LEFT_BRACE) {
+			return nil
+		}
+		exp.Catch = p.parseBlockStatement()
+	}
+
+	if p.TokenIs(p.peekToken, token.FINALLY) {
+		p.nextToken()
+		if !p.expectNextToken(token.LEFT_BRACE) {
+			return nil
+		}
+		exp.Finally = p.parseBlockStatement()
+	}
+
+	if exp.Catch == nil && exp.Finally == nil {
+		p.errors = append(p.errors, "expected catch or finally after try block")
+		return nil
+	}
+
+	return exp
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := ast.BlockStatement{
 		Token: p.currToken,
@@ -489,37 +795,37 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return &block
 }
 
-func (p *Parser) parseFunctionParams() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// (x, [a, b], y = 1, ...rest)
+func (p *Parser) parseFunctionParams(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []ast.Expression{}
 
-	// empty body
-	if p.TokenIs(p.peekToken, token.RIGHT_PAREN) {
+	for !p.TokenIs(p.peekToken, token.RIGHT_PAREN) {
 		p.nextToken()
-		return identifiers
-	}
 
-	p.nextToken()
-	ident := &ast.Identifier{
-		Token: p.currToken,
-		Value: p.currToken.Literal,
-	}
-	// append the first arg
-	identifiers = append(identifiers, ident)
+		if p.TokenIs(p.currToken, token.ELLIPSIS) {
+			if !p.expectNextToken(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{
+				Token: p.currToken,
+				Value: p.currToken.Literal,
+			}
+			// the rest has to be the last param
+			break
+		}
 
-	for p.TokenIs(p.peekToken, token.COMMA) {
-		p.nextToken()
-		p.nextToken()
-		ident := &ast.Identifier{
-			Token: p.currToken,
-			Value: p.currToken.Literal,
+		param := p.parsePatternElement()
+		if param == nil {
+			return false
+		}
+		lit.Parameters = append(lit.Parameters, param)
+
+		if !p.TokenIs(p.peekToken, token.RIGHT_PAREN) && !p.expectNextToken(token.COMMA) {
+			return false
 		}
-		identifiers = append(identifiers, ident)
-	}
-	if !p.expectNextToken(token.RIGHT_PAREN) {
-		return nil
 	}
 
-	return identifiers
+	return p.expectNextToken(token.RIGHT_PAREN)
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
@@ -535,7 +841,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	//		myFunc(x, y, fn(x, y) { return x > y; });
 	//		( ) --> empty
 	//		(1 + 2, 3 * 8)
-	lit.Parameters = p.parseFunctionParams()
+	if !p.parseFunctionParams(lit) {
+		return nil
+	}
 
 	if !p.expectNextToken(token.LEFT_BRACE) {
 		return nil
@@ -552,10 +860,77 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Function: function,
 	}
 
-	exp.Arguments = p.parseListExpression(token.RIGHT_PAREN)
+	exp.Arguments = p.parseCallArguments()
 	return exp
 }
 
+// like parseListExpression, but args can be named : f(1, ...rest, name: "x")
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+	named := false
+
+	for !p.TokenIs(p.peekToken, token.RIGHT_PAREN) {
+		p.nextToken()
+
+		arg := p.parseCallArgument()
+		if arg == nil {
+			return nil
+		}
+		if _, ok := arg.(*ast.NamedArgument); ok {
+			named = true
+		} else if named {
+			msg := fmt.Sprintf("positional argument %s after named arguments", arg)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		args = append(args, arg)
+
+		if !p.TokenIs(p.peekToken, token.RIGHT_PAREN) && !p.expectNextToken(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectNextToken(token.RIGHT_PAREN) {
+		return nil
+	}
+
+	return args
+}
+
+func (p *Parser) parseCallArgument() ast.Expression {
+	if !p.TokenIs(p.currToken, token.IDENT) || !p.TokenIs(p.peekToken, token.COLON) {
+		return p.parseExpression(LOWEST)
+	}
+
+	na := &ast.NamedArgument{
+		Name: &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		},
+	}
+	p.nextToken()
+	na.Token = p.currToken
+	p.nextToken()
+
+	na.Value = p.parseExpression(LOWEST)
+	if na.Value == nil {
+		return nil
+	}
+	return na
+}
+
+// ...list
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	se := &ast.SpreadExpression{
+		Token: p.currToken,
+	}
+
+	p.nextToken()
+
+	se.Value = p.parseExpression(PREFIX)
+
+	return se
+}
+
 func (p *Parser) parseHashLiteral() ast.Expression {
 	m := &ast.HashLiteral{
 		Token: p.currToken,
@@ -576,6 +951,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		value := p.parseExpression(LOWEST)
 
 		m.Store[key] = value
+		m.Keys = append(m.Keys, key)
 
 		if !p.TokenIs(p.peekToken, token.RIGHT_BRACE) && !p.expectNextToken(token.COMMA) {
 			return nil
@@ -586,3 +962,242 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	}
 	return m
 }
+
+// a pattern on the left side of a let or as a function param : x, [a, b], {name, age: years}
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.currToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
+	case token.LEFT_BRACKET:
+		return p.parseListPattern(p.parsePatternElement)
+	case token.LEFT_BRACE:
+		return p.parseHashPattern(p.parsePatternElement)
+	default:
+		msg := fmt.Sprintf("expected an identifier or a pattern, got %s instead", p.currToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
+
+// a pattern with an optional default : x = 1, [a, b] = []
+func (p *Parser) parsePatternElement() ast.Expression {
+	target := p.parsePattern()
+	if target == nil {
+		return nil
+	}
+	if !p.TokenIs(p.peekToken, token.ASSIGN) {
+		return target
+	}
+	p.nextToken()
+
+	dp := &ast.DefaultPattern{
+		Token:  p.currToken,
+		Target: target,
+	}
+	p.nextToken()
+	dp.Default = p.parseExpression(ASSIGN)
+
+	return dp
+}
+
+// [a, b = 1, ...rest], the elements are parsed with parseElement
+func (p *Parser) parseListPattern(parseElement func() ast.Expression) ast.Expression {
+	lp := &ast.ListPattern{
+		Token: p.currToken,
+	}
+
+	for !p.TokenIs(p.peekToken, token.RIGHT_BRACKET) {
+		p.nextToken()
+
+		if p.TokenIs(p.currToken, token.ELLIPSIS) {
+			if !p.expectNextToken(token.IDENT) {
+				return nil
+			}
+			lp.Rest = &ast.Identifier{
+				Token: p.currToken,
+				Value: p.currToken.Literal,
+			}
+			// the rest has to be the last element
+			break
+		}
+
+		el := parseElement()
+		if el == nil {
+			return nil
+		}
+		lp.Elements = append(lp.Elements, el)
+
+		if !p.TokenIs(p.peekToken, token.RIGHT_BRACKET) && !p.expectNextToken(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectNextToken(token.RIGHT_BRACKET) {
+		return nil
+	}
+	return lp
+}
+
+// {name, age: years, "first name": first, city = "Cairo"}, the values are parsed with parseElement
+func (p *Parser) parseHashPattern(parseElement func() ast.Expression) ast.Expression {
+	hp := &ast.HashPattern{
+		Token: p.currToken,
+	}
+
+	for !p.TokenIs(p.peekToken, token.RIGHT_BRACE) {
+		p.nextToken()
+
+		if !p.TokenIs(p.currToken, token.IDENT) && !p.TokenIs(p.currToken, token.STRING) {
+			msg := fmt.Sprintf("expected a key in hash pattern, got %s instead", p.currToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		pair := &ast.HashPatternPair{Key: p.currToken.Literal}
+
+		if p.TokenIs(p.peekToken, token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			pair.Value = parseElement()
+		} else if p.TokenIs(p.currToken, token.IDENT) {
+			// {name} is {name: name}
+			pair.Value = parseElement()
+		} else {
+			p.peekError(token.COLON)
+			return nil
+		}
+		if pair.Value == nil {
+			return nil
+		}
+		hp.Pairs = append(hp.Pairs, pair)
+
+		if !p.TokenIs(p.peekToken, token.RIGHT_BRACE) && !p.expectNextToken(token.COMMA) {
+			return nil
+		}
+	}
+	if !p.expectNextToken(token.RIGHT_BRACE) {
+		return nil
+	}
+	return hp
+}
+
+// match (<expression>) { <pattern> [if <guard>] => <expression or block>, ... }
+// the comma after an arm is optional if its body is a block
+func (p *Parser) parseMatchExpression() ast.Expression {
+	me := &ast.MatchExpression{
+		Token: p.currToken,
+	}
+
+	if !p.expectNextToken(token.LEFT_PAREN) {
+		return nil
+	}
+	p.nextToken()
+	me.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectNextToken(token.RIGHT_PAREN) {
+		return nil
+	}
+	if !p.expectNextToken(token.LEFT_BRACE) {
+		return nil
+	}
+
+	for !p.TokenIs(p.peekToken, token.RIGHT_BRACE) {
+		p.nextToken()
+
+		arm := &ast.MatchArm{}
+		arm.Pattern = p.parseMatchPattern()
+		if arm.Pattern == nil {
+			return nil
+		}
+
+		if p.TokenIs(p.peekToken, token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectNextToken(token.ARROW) {
+			return nil
+		}
+
+		isBlock := p.TokenIs(p.peekToken, token.LEFT_BRACE)
+		if isBlock {
+			p.nextToken()
+			arm.Body = p.parseBlockStatement()
+		} else {
+			p.nextToken()
+			stmt := &ast.ExpressionStatement{
+				Token:      p.currToken,
+				Expression: p.parseExpression(LOWEST),
+			}
+			arm.Body = &ast.BlockStatement{
+				Token:      stmt.Token,
+				Statements: []ast.Statement{stmt},
+			}
+		}
+		me.Arms = append(me.Arms, arm)
+
+		if p.TokenIs(p.peekToken, token.COMMA) {
+			p.nextToken()
+		} else if !isBlock && !p.TokenIs(p.peekToken, token.RIGHT_BRACE) {
+			p.peekError(token.COMMA)
+			return nil
+		}
+	}
+	if !p.expectNextToken(token.RIGHT_BRACE) {
+		return nil
+	}
+	return me
+}
+
+// literals, bindings (_ matches anything without binding), type names, list and hash patterns :
+// 1, -1, "circle", true, n, _, int, [first, ...rest], {"kind": "circle", r}
+func (p *Parser) parseMatchPattern() ast.Expression {
+	switch p.currToken.Type {
+	case token.INT:
+		return p.parseIntegerLiteral()
+	case token.STRING:
+		return p.parseStringLiteral()
+	case token.TRUE, token.FALSE:
+		return p.parseBooleanExpression()
+	case token.NEG:
+		pe := &ast.PrefixExpression{
+			Token:    p.currToken,
+			Operator: p.currToken.Literal,
+		}
+		if !p.expectNextToken(token.INT) {
+			return nil
+		}
+		pe.Right = p.parseIntegerLiteral()
+		if pe.Right == nil {
+			return nil
+		}
+		return pe
+	case token.IDENT:
+		if ast.TypePatternNames[p.currToken.Literal] {
+			return &ast.TypePattern{
+				Token: p.currToken,
+				Name:  p.currToken.Literal,
+			}
+		}
+		return &ast.Identifier{
+			Token: p.currToken,
+			Value: p.currToken.Literal,
+		}
+	case token.FUNC:
+		// fn is a keyword, but it's also the type pattern of functions
+		return &ast.TypePattern{
+			Token: p.currToken,
+			Name:  p.currToken.Literal,
+		}
+	case token.LEFT_BRACKET:
+		return p.parseListPattern(p.parseMatchPattern)
+	case token.LEFT_BRACE:
+		return p.parseHashPattern(p.parseMatchPattern)
+	default:
+		msg := fmt.Sprintf("expected a match pattern, got %s instead", p.currToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+}
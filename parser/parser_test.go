@@ -37,6 +37,67 @@ func TestLetStatements(t *testing.T) {
 		}
 	}
 }
+func TestLetPatternStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = x;", "let [a, b] = x;"},
+		{"let [a, b = 1 + 1, ...rest] = x;", "let [a, b = (1 + 1), ...rest] = x;"},
+		{"let [[a, b], {c}] = x;", "let [[a, b], {c: c}] = x;"},
+		{"let {name, age: years} = x;", "let {name: name, age: years} = x;"},
+		{`let {"first name": first, city = "Cairo"} = x;`, "let {first name: first, city: city = Cairo} = x;"},
+		{"let {point: [x, y]} = p;", "let {point: [x, y]} = p;"},
+		{"let [] = x;", "let [] = x;"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Pattern == nil {
+			t.Fatalf("stmt.Pattern is nil")
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestInvalidLetPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, 1] = x;", "expected an identifier or a pattern, got INT instead"},
+		{"let [...rest, a] = x;", "expected next token to be ], got , instead"},
+		{"let {1: a} = x;", "expected a key in hash pattern, got INT instead"},
+		{`let {"name"} = x;`, "expected next token to be :, got } instead"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, stat ast.Statement, name string) bool {
 	// checking the token literal
 	if stat.TokenLiteral() != "let" {
@@ -280,6 +341,7 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 - 5;", 5, "-", 5},
 		{"5 * 5;", 5, "*", 5},
 		{"5 / 5;", 5, "/", 5},
+		{"5 % 5;", 5, "%", 5},
 		{"5 > 5;", 5, ">", 5},
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
@@ -321,6 +383,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-a * b",
 			"((-a) * b)",
 		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b == c",
+			"(a & (b == c))",
+		},
+		{
+			"a << 1 + b < c >> 2",
+			"((a << (1 + b)) < (c >> 2))",
+		},
+		{
+			"~a & -b",
+			"((~a) & (-b))",
+		},
 		{
 			"!-a",
 			"(!(-a))",
@@ -409,6 +487,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"a + add(b * c) + d",
 			"((a + add((b * c))) + d)",
 		},
+		{
+			"a + 1 is b < c",
+			"((a + 1) is (b < c))",
+		},
+		{
+			"a + 1 in b == c",
+			"(((a + 1) in b) == c)",
+		},
+		{
+			"a[1:2]",
+			"(a[1:2])",
+		},
+		{
+			"a[:b + 1][::-1]",
+			"((a[:(b + 1)])[::(-1)])",
+		},
+		{
+			"a[1:][:2:3]",
+			"((a[1:])[:2:3])",
+		},
+		{
+			"a[:]",
+			"(a[:])",
+		},
 		{
 			"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))",
 			"add(a, b, 1, (2 * 3), (4 + 5), add(6, (7 * 8)))",
@@ -425,6 +527,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a.b.c * d",
+			"(((a.b).c) * d)",
+		},
+		{
+			"a.b(1) + c.d[0]",
+			"((a.b)(1) + ((c.d)[0]))",
+		},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -731,6 +841,101 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestFunctionPatternParameterParsing(t *testing.T) {
+	input := "fn(a, [b, c], {d, e: f}) {};"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function := stmt.Expression.(*ast.FunctionLiteral)
+
+	if len(function.Parameters) != 3 {
+		t.Fatalf("length parameters wrong. want 3, got=%d\n", len(function.Parameters))
+	}
+	testLiteralExpression(t, function.Parameters[0], "a")
+	if _, ok := function.Parameters[1].(*ast.ListPattern); !ok {
+		t.Errorf("param 1 is not *ast.ListPattern. got=%T", function.Parameters[1])
+	}
+	if _, ok := function.Parameters[2].(*ast.HashPattern); !ok {
+		t.Errorf("param 2 is not *ast.HashPattern. got=%T", function.Parameters[2])
+	}
+	if function.String() != "fn(a, [b, c], {d: d, e: f}) " {
+		t.Errorf("wrong function string. got=%q", function.String())
+	}
+}
+
+func TestFunctionDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 10) {}", "fn(a, b = 10) "},
+		{"fn(first, ...rest) {}", "fn(first, ...rest) "},
+		{"fn(a = 1, [b, c] = [], ...rest) {}", "fn(a = 1, [b, c] = [], ...rest) "},
+		{"fn(...all) {}", "fn(...all) "},
+		{"f(...list)", "f(...list)"},
+		{"f(1, ...a, ...b)", "f(1, ...a, ...b)"},
+		{"f(1, name: 2 + 3)", "f(1, name: (2 + 3))"},
+		{"[0, ...rest]", "[0, ...rest]"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.Parse()
+		checkParserErrors(t, p)
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestFunctionLiteralWithName(t *testing.T) {
+	input := "let myFunction = fn() { };"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T", program.Statements[0])
+	}
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+	if function.Name != "myFunction" {
+		t.Fatalf("function literal name wrong. want 'myFunction', got=%q\n", function.Name)
+	}
+}
+
+func TestInvalidCallArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"f(a: 1, 2)", "positional argument 2 after named arguments"},
+		{"fn(...rest, a) {}", "expected next token to be ), got , instead"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
 // extend it later
 func TestParseListLiteral(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
@@ -770,6 +975,91 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingMemberExpressions(t *testing.T) {
+	input := "person.name = 1 + 1"
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	assign, ok := stmt.Expression.(*ast.AssignExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+	}
+	member, ok := assign.Target.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("assign.Target not *ast.MemberExpression. got=%T", assign.Target)
+	}
+	if !testIdentifier(t, member.Object, "person") {
+		return
+	}
+	if !testIdentifier(t, member.Property, "name") {
+		return
+	}
+	if !testInfixExpression(t, assign.Value, 1, "+", 1) {
+		return
+	}
+}
+
+func TestParsingAssignTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 1", "x = 1"},
+		{"x = y = 1 + 2", "x = y = (1 + 2)"},
+		{"a[i][j] = v", "((a[i])[j]) = v"},
+		{`m["k"]["inner"] = v * 2`, "((m[k])[inner]) = (v * 2)"},
+		{"obj.field.sub = v", "((obj.field).sub) = v"},
+		{"a[0].b = c[1]", "((a[0]).b) = (c[1])"},
+		{"x += 1 * 2", "x += (1 * 2)"},
+		{"a[i] -= b %= 2", "(a[i]) -= b %= 2"},
+		{"obj.count *= 3", "(obj.count) *= 3"},
+		{"x++ + ++y", "((x++) + (++y))"},
+		{"-a[i]--", "(-((a[i])--))"},
+		{"obj.n--", "((obj.n)--)"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.Parse()
+		checkParserErrors(t, p)
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestParsingInvalidAssignTargets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 = 2", "invalid assignment target: 1"},
+		{"a + b = 2", "invalid assignment target: (a + b)"},
+		{"f(x) = 2", "invalid assignment target: f(x)"},
+		{"1 += 2", "invalid assignment target: 1"},
+		{"5++", "invalid assignment target: 5"},
+		{"--(a + b)", "invalid assignment target: (a + b)"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 	l := lexer.New(input)
@@ -887,3 +1177,311 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		testFunc(value)
 	}
 }
+
+func TestMatchExpressionParsing(t *testing.T) {
+	input := `match (x + 1) {
+		0 => "zero",
+		-1 => "minus one",
+		[first, ...rest] if first > 0 => { first }
+		{"kind": "circle", r} => r * r,
+		int => "int",
+		fn => "function",
+		_ => x
+	}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, exp.Subject, "x", "+", 1) {
+		return
+	}
+
+	expected := []struct {
+		pattern string
+		guard   string
+		body    string
+	}{
+		{"0", "", "zero"},
+		{"(-1)", "", "minus one"},
+		{"[first, ...rest]", "(first > 0)", "first"},
+		{"{kind: circle, r: r}", "", "(r * r)"},
+		{"int", "", "int"},
+		{"fn", "", "function"},
+		{"_", "", "x"},
+	}
+	if len(exp.Arms) != len(expected) {
+		t.Fatalf("wrong number of arms. want %d, got=%d", len(expected), len(exp.Arms))
+	}
+	for i, tt := range expected {
+		arm := exp.Arms[i]
+		if arm.Pattern.String() != tt.pattern {
+			t.Errorf("arms[%d] wrong pattern. want %q, got=%q", i, tt.pattern, arm.Pattern.String())
+		}
+		guard := ""
+		if arm.Guard != nil {
+			guard = arm.Guard.String()
+		}
+		if guard != tt.guard {
+			t.Errorf("arms[%d] wrong guard. want %q, got=%q", i, tt.guard, guard)
+		}
+		if arm.Body.String() != tt.body {
+			t.Errorf("arms[%d] wrong body. want %q, got=%q", i, tt.body, arm.Body.String())
+		}
+	}
+	if _, ok := exp.Arms[4].Pattern.(*ast.TypePattern); !ok {
+		t.Errorf("arms[4] pattern is not ast.TypePattern. got=%T", exp.Arms[4].Pattern)
+	}
+}
+
+// every name of ast.TypePatternNames parses as a type pattern, and fn even though it's a keyword
+func TestTypePatternNames(t *testing.T) {
+	names := []string{"fn"}
+	for name := range ast.TypePatternNames {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		program := New(lexer.New("match (x) { " + name + " => 1 }")).Parse()
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		pattern := stmt.Expression.(*ast.MatchExpression).Arms[0].Pattern
+		if tp, ok := pattern.(*ast.TypePattern); !ok || tp.Name != name {
+			t.Errorf("%q: expected a type pattern, got=%T (%s)", name, pattern, pattern)
+		}
+	}
+}
+
+func TestInvalidMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { a + 1 => 1 }", "expected next token to be =>, got + instead"},
+		{"match (x) { (1) => 1 }", "expected a match pattern, got ( instead"},
+		{"match x { _ => 1 }", "expected next token to be (, got IDENT instead"},
+		{"match (x) { 1 => 1 2 => 2 }", "expected next token to be ,, got INT instead"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestTryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { f(x) } catch (e) { e }", "try f(x) catch(e) e"},
+		{"try { f(x) } catch { 0 }", "try f(x) catch 0"},
+		{"try { f(x) } finally { g() }", "try f(x) finally g()"},
+		{"try { 1 } catch (err) { 2 } finally { 3 }", "try 1 catch(err) 2 finally 3"},
+		{"throw x + 1;", "throw (x + 1);"},
+		{`throw error("bad", 1)`, "throw error(bad, 1);"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try { 1 }", "expected catch or finally after try block"},
+		{"try { 1 } catch (1) { 2 }", "expected next token to be IDENT, got INT instead"},
+		{"try 1 catch { 2 }", "expected next token to be {, got INT instead"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestHashLiteralKeyOrder(t *testing.T) {
+	input := `{"z": 1, "a": 2, 3: x, true: "t"}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	expected := "{z:1, a:2, 3:x, true:t}"
+	if program.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestIllegalTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1;\nlet s = \"oops;\nlet y = 2;", "unterminated string starting at line 2"},
+		{`let s = "bad \q escape";`, `unknown escape sequence \q in string at line 1`},
+		{"1 + @", "unexpected character '@' at line 1, column 5"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestInterpolatedStringParsing(t *testing.T) {
+	input := `"Hello ${name}, you have ${len(items) + 1} items"`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.Parse()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	is, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+	if len(is.Parts) != 5 {
+		t.Fatalf("wrong number of parts. expected=5, got=%d", len(is.Parts))
+	}
+	if !testIdentifier(t, is.Parts[1], "name") {
+		return
+	}
+	if is.Parts[3].String() != "(len(items) + 1)" {
+		t.Errorf("wrong expression. got=%q", is.Parts[3].String())
+	}
+	if is.String() != "Hello ${name}, you have ${(len(items) + 1)} items" {
+		t.Errorf("wrong String(). got=%q", is.String())
+	}
+}
+
+func TestInvalidInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "empty ${} in string at line 1"},
+		{`"a ${x y} b"`, "expected } after the expression in ${x y}, got y instead"},
+		{`"a ${1 +} b"`, "No prefix parse function for EOF found"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestNumericLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0", 0},
+		{"1_000_000", 1000000},
+		{"0x1F", 31},
+		{"0XfF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"0xFFFF_FFFF", 4294967295},
+		{"9223372036854775807", 9223372036854775807},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.Parse()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		integ, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Errorf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+			continue
+		}
+		if integ.Value != tt.expected {
+			t.Errorf("wrong value for %s. expected=%d, got=%d", tt.input, tt.expected, integ.Value)
+		}
+	}
+}
+
+func TestInvalidNumericLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0x", "invalid integer literal 0x: expected hex digits after 0x"},
+		{"0b", "invalid integer literal 0b: expected binary digits after 0b"},
+		{"1__0", "invalid integer literal 1__0: underscores can only be used between digits"},
+		{"10_", "invalid integer literal 10_: underscores can only be used between digits"},
+		{"0x_", "invalid integer literal 0x_: underscores can only be used between digits"},
+		{"0b_1010", "invalid integer literal 0b_1010: underscores can only be used between digits"},
+		{"0x1G", "invalid integer literal 0x1G: 'G' isn't a valid hex digit"},
+		{"0o78", "invalid integer literal 0o78: '8' isn't a valid octal digit"},
+		{"0b102", "invalid integer literal 0b102: '2' isn't a valid binary digit"},
+		{"12abc", "invalid integer literal 12abc: 'a' isn't a valid decimal digit"},
+		{"0755", "invalid integer literal 0755: leading zeros aren't allowed, use 0o for octal"},
+		{"9223372036854775808", "invalid integer literal 9223372036854775808: out of range for a 64 bit integer"},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.Parse()
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
@@ -0,0 +1,424 @@
+/*
+A small line editor for the REPL, used when stdin is a terminal :-
+
+	Left/Right, Ctrl-B/Ctrl-F     move the cursor
+	Home/End, Ctrl-A/Ctrl-E       go to the start/end of the line
+	Up/Down, Ctrl-P/Ctrl-N        browse the history
+	Backspace, Delete, Ctrl-D     delete a character (Ctrl-D on an empty line exits)
+	Ctrl-K, Ctrl-U, Ctrl-W        delete to the end, to the start, the word before the cursor
+	Ctrl-R                        search the history backwards
+	Tab                           complete keywords, builtins and bound names
+	Ctrl-L                        clear the screen
+	Ctrl-C                        drop the input
+
+The terminal is only raw while a line is read, so the output of the programs isn't changed.
+The line is highlighted as it's typed (unless NO_COLOR is set and not empty), each line on its own : the rest of a multiline string isn't colored as a string.
+A multiline input is one entry of the history, it's shown on one line with its line breaks as ↵.
+Characters are assumed to be one column wide.
+*/
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Ctrl-C, the input being typed is dropped
+var errInterrupted = errors.New("interrupted")
+
+// reads a line of input after showing the prompt, and keeps the inputs once they're complete
+type lineReader interface {
+	readLine(prompt string) (string, error)
+	addHistory(input string)
+}
+
+// plain line reading, when stdin isn't a terminal (a pipe, a file) or it can't be made raw
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// the plain reader has no history
+func (r *plainReader) addHistory(input string) {}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyCtrlJ     = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// the escape sequences are read as one key, they're negative so they can't be mistaken for a character
+const (
+	keyUnknown rune = -(iota + 1)
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+)
+
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	history  *history
+	complete func(prefix string) []string
+	raw      func() (func(), error) // puts the terminal in raw mode, nil if the input is already raw
+	colorize func(string) string    // highlights the line as it's typed, nil keeps it plain
+
+	// the line being edited
+	prompt    string
+	buf       []rune
+	pos       int
+	histIndex int    // the history line shown, len(history.lines) is the line being typed
+	typed     []rune // the line being typed, kept while browsing the history
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	e.prompt, e.buf, e.pos = prompt, []rune{}, 0
+	e.histIndex = len(e.history.lines)
+	e.refresh()
+
+	var key rune
+	pending := false // the key that ended a reverse search, it's handled like any other key
+	for {
+		if !pending {
+			var err error
+			key, err = e.readKey()
+			if err != nil {
+				return "", err
+			}
+		}
+		pending = false
+		switch key {
+		case keyEnter, keyCtrlJ:
+			return e.submit(), nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyDelete:
+			e.deleteAt(e.pos)
+		case keyLeft, keyCtrlB:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyRight, keyCtrlF:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyHome, keyCtrlA:
+			e.pos = 0
+		case keyEnd, keyCtrlE:
+			e.pos = len(e.buf)
+		case keyUp, keyCtrlP:
+			e.showHistory(e.histIndex - 1)
+		case keyDown, keyCtrlN:
+			e.showHistory(e.histIndex + 1)
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case keyTab:
+			e.completeWord()
+		case keyCtrlR:
+			next, err := e.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+			if next != 0 {
+				key, pending = next, true
+				continue
+			}
+		default:
+			// the other control keys are ignored
+			if key >= ' ' {
+				e.insert([]rune{key})
+			}
+		}
+		e.refresh()
+	}
+}
+
+func (e *editor) submit() string {
+	e.pos = len(e.buf)
+	e.refresh()
+	io.WriteString(e.out, "\r\n")
+	return string(e.buf)
+}
+
+func (e *editor) addHistory(input string) {
+	e.history.add(input)
+}
+
+// read a key, the escape sequences of the arrows, Home, End and Delete are read as one key
+func (e *editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEscape {
+		return r, err
+	}
+	// ESC [ A, or ESC O A from some terminals, with ESC [ 3 ~ for Delete
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != '[' && r != 'O' {
+		return keyUnknown, nil
+	}
+	params := ""
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if r < 0x40 || r > 0x7e {
+			params += string(r)
+			continue
+		}
+		switch r {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		case 'H':
+			return keyHome, nil
+		case 'F':
+			return keyEnd, nil
+		case '~':
+			switch params {
+			case "1", "7":
+				return keyHome, nil
+			case "4", "8":
+				return keyEnd, nil
+			case "3":
+				return keyDelete, nil
+			}
+		}
+		return keyUnknown, nil
+	}
+}
+
+// redraw the line and put the cursor back where it is in the line
+func (e *editor) refresh() {
+	var out strings.Builder
+	out.WriteString("\r")
+	out.WriteString(e.prompt)
+	line := string(e.buf)
+	if e.colorize != nil {
+		line = e.colorize(line)
+	}
+	out.WriteString(strings.ReplaceAll(line, "\n", "↵"))
+	out.WriteString("\x1b[K")
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(&out, "\x1b[%dD", back)
+	}
+	io.WriteString(e.out, out.String())
+}
+
+func (e *editor) insert(runes []rune) {
+	rest := append([]rune{}, e.buf[e.pos:]...)
+	e.buf = append(append(e.buf[:e.pos], runes...), rest...)
+	e.pos += len(runes)
+}
+
+func (e *editor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+// delete the word before the cursor, and the spaces after it
+func (e *editor) deleteWord() {
+	start := e.pos
+	for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+// show the i-th history line, going past the newest one gives back the line being typed
+func (e *editor) showHistory(i int) {
+	if i < 0 || i > len(e.history.lines) {
+		return
+	}
+	if e.histIndex == len(e.history.lines) {
+		e.typed = e.buf
+	}
+	e.histIndex = i
+	if i == len(e.history.lines) {
+		e.buf = e.typed
+	} else {
+		e.buf = []rune(e.history.lines[i])
+	}
+	e.pos = len(e.buf)
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc)
+}
+
+// complete the word before the cursor : one match is inserted, several are completed as far as they agree, then listed
+func (e *editor) completeWord() {
+	start := e.pos
+	for start > 0 && isWordChar(e.buf[start-1]) {
+		start--
+	}
+	prefix := e.buf[start:e.pos]
+	if len(prefix) == 0 || e.complete == nil {
+		return
+	}
+	matches := e.complete(string(prefix))
+	if len(matches) == 0 {
+		io.WriteString(e.out, "\a")
+		return
+	}
+	common := []rune(commonPrefix(matches))
+	if len(common) > len(prefix) {
+		e.insert(common[len(prefix):])
+		return
+	}
+	if len(matches) > 1 {
+		io.WriteString(e.out, "\r\n"+strings.Join(matches, "  ")+"\r\n")
+	}
+}
+
+func commonPrefix(words []string) string {
+	common := []rune(words[0])
+	for _, word := range words[1:] {
+		runes := []rune(word)
+		i := 0
+		for i < len(common) && i < len(runes) && common[i] == runes[i] {
+			i++
+		}
+		common = common[:i]
+	}
+	return string(common)
+}
+
+// Ctrl-R : the history is searched backwards as the query is typed, Ctrl-R again finds an older match.
+// Ctrl-G or Ctrl-C gives back the line as it was (the key returned is 0),
+// the other keys take the match and are returned to be handled as usual : Enter runs it, an arrow moves in it
+func (e *editor) reverseSearch() (rune, error) {
+	lines := e.history.lines
+	query := []rune{}
+	found := len(lines)
+	failed := false
+	for {
+		match := ""
+		if found < len(lines) {
+			match = lines[found]
+		}
+		status := "reverse-i-search"
+		if failed {
+			status = "failed reverse-i-search"
+		}
+		fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", status, string(query), strings.ReplaceAll(match, "\n", "↵"))
+
+		key, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case key == keyCtrlR:
+			if i := e.history.search(string(query), found); i != -1 {
+				found = i
+			}
+		case key == keyBackspace || key == keyCtrlH:
+			if len(query) == 0 {
+				continue
+			}
+			query = query[:len(query)-1]
+			found, failed = len(lines), false
+			if len(query) > 0 {
+				found = e.history.search(string(query), len(lines))
+			}
+		case key == keyCtrlG || key == keyCtrlC:
+			return 0, nil
+		case key >= ' ':
+			query = append(query, key)
+			// the current match is kept if it still matches
+			from := found + 1
+			if from > len(lines) {
+				from = len(lines)
+			}
+			i := e.history.search(string(query), from)
+			failed = i == -1
+			if !failed {
+				found = i
+			}
+		default:
+			if found < len(lines) {
+				e.buf = []rune(lines[found])
+				e.pos = len(e.buf)
+				e.histIndex = found
+			}
+			return key, nil
+		}
+		if found == -1 {
+			found, failed = len(lines), true
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(keys string, lines ...string) *editor {
+	words := []string{"print", "push", "pop", "let"}
+	return &editor{
+		in:      bufio.NewReader(strings.NewReader(keys)),
+		out:     &bytes.Buffer{},
+		history: &history{lines: lines},
+		complete: func(prefix string) []string {
+			matches := []string{}
+			for _, word := range words {
+				if strings.HasPrefix(word, prefix) {
+					matches = append(matches, word)
+				}
+			}
+			return matches
+		},
+	}
+}
+
+func TestEditorKeys(t *testing.T) {
+	history := []string{"let x = 1", "print(x)", "let y = 2"}
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"abc\r", "abc"},
+		{"abc\x1b[D\x1b[DX\r", "aXbc"},
+		{"abc\x01X\x05Y\r", "XabcY"},
+		{"abc\x1b[H\x1b[CX\x1b[FY\r", "aXbcY"},
+		{"abc\x7f\r", "ab"},
+		{"abc\x1b[D\x1b[D\x1b[3~\r", "ac"},
+		{"abc\x02\x02\x04\r", "ac"},
+		{"let hello  world\x17\r", "let hello  "},
+		{"abc\x1b[D\x0b\r", "ab"},
+		{"abc\x1b[D\x15\r", "c"},
+		{"héllo\x1b[D\x1b[D\x1b[D\x1b[D\x7f\r", "éllo"},
+		{"a\x1b[5~\x07b\r", "ab"},
+		// history
+		{"\x1b[A\r", "let y = 2"},
+		{"\x1b[A\x1b[A\r", "print(x)"},
+		{"\x1b[A\x1b[A\x1b[A\x1b[A\r", "let x = 1"},
+		{"typed\x1b[A\x1b[B\r", "typed"},
+		{"\x10\x10\x0e\r", "let y = 2"},
+		{"\x1b[A!\r", "let y = 2!"},
+		// completion
+		{"pr\t(1)\r", "print(1)"},
+		{"p\t\r", "p"},
+		{"pu\t\r", "push"},
+		{"x = le\t\r", "x = let"},
+		{"zz\t\r", "zz"},
+		// reverse search
+		{"\x12let\r", "let y = 2"},
+		{"\x12let\x12\r", "let x = 1"},
+		{"\x12let\x12\x12\r", "let x = 1"},
+		{"\x12pri\x1b[C!\r", "print(x)!"},
+		{"\x12pri\x01#\r", "#print(x)"},
+		{"\x12y\x1b[D\x1b[D\x7f\r", "let y  2"},
+		{"\x12x\x7f\x7fy\r", "let y = 2"},
+		{"abc\x12zz\x07\r", "abc"},
+		{"abc\x12let\x03\r", "abc"},
+		{"\x12zz\r", ""},
+		// a failing search keeps the last match
+		{"\x12nope\r", "print(x)"},
+	}
+	for _, tt := range tests {
+		e := newTestEditor(tt.keys, history...)
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorEnd(t *testing.T) {
+	e := newTestEditor("abc\x03\x04")
+	if _, err := e.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("Ctrl-C: expected errInterrupted, got %v", err)
+	}
+	if _, err := e.readLine(PROMPT); err != io.EOF {
+		t.Errorf("Ctrl-D: expected io.EOF, got %v", err)
+	}
+	if len(e.history.lines) != 0 {
+		t.Errorf("the dropped line went in the history: %q", e.history.lines)
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	e := newTestEditor("one\rtwo\rtwo\r\r\x1b[A\x1b[A\r[1,\r2]\r\x1b[A\r")
+	inputs := []string{}
+	for i := 0; i < 6; i++ {
+		input, _ := readInput(e)
+		inputs = append(inputs, input)
+	}
+	if got := strings.Join(e.history.lines, ","); got != "one,two,one,[1,\n2]" {
+		t.Errorf("wrong history: %q", got)
+	}
+	// the multiline input comes back whole
+	if inputs[5] != "[1,\n2]" {
+		t.Errorf("wrong input from the history: %q", inputs[5])
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trash", "history")
+	h := loadHistory(path)
+	h.add("let x = 1")
+	h.add("x + 1")
+	h.add("x + 1")
+	h.add("let s = \"a\\n\"\ns")
+
+	loaded := loadHistory(path)
+	if strings.Join(loaded.lines, ",") != "let x = 1,x + 1,let s = \"a\\n\"\ns" {
+		t.Fatalf("wrong history loaded: %q", loaded.lines)
+	}
+
+	for i := 0; i < HISTORY_SIZE+5; i++ {
+		loaded.add(strings.Repeat("x", i%3+1) + string(rune('a'+i%26)))
+	}
+	if reloaded := loadHistory(path); len(reloaded.lines) != HISTORY_SIZE {
+		t.Errorf("the history file isn't trimmed: %d lines", len(reloaded.lines))
+	}
+}
+
+func TestSessionComplete(t *testing.T) {
+	s := newSession(&bytes.Buffer{}, false)
+	s.run("let result = 1; let rex = 2")
+	got := strings.Join(s.complete("re"), ",")
+	expected := "reduce,remove,repeat,replace,repr,rest,result,return,reverse,rex"
+	if got != expected {
+		t.Errorf("expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestEditorColorize(t *testing.T) {
+	e := newTestEditor("let\r")
+	e.colorize = func(line string) string { return "<" + line + ">" }
+	e.readLine(PROMPT)
+	if out := e.out.(*bytes.Buffer).String(); !strings.HasSuffix(out, "\r>> <let>\x1b[K\r\n") {
+		t.Errorf("the line isn't colorized: %q", out)
+	}
+}
@@ -0,0 +1,115 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the number of lines kept in the history file
+const HISTORY_SIZE = 1000
+
+// the inputs entered in the REPL, oldest first. They're saved in a file so they're still there in the next session,
+// one per line : the line breaks of a multiline input are written \n (and a backslash \\)
+type history struct {
+	lines []string
+	path  string // "" keeps the history in memory only
+}
+
+// the history file is in the user's config dir : ~/.config/trash/history on linux
+func historyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "trash", "history")
+}
+
+// load the history from its file, a missing or unreadable file is an empty history
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.lines = append(h.lines, unescapeHistory(scanner.Text()))
+	}
+	if len(h.lines) > HISTORY_SIZE {
+		h.lines = h.lines[len(h.lines)-HISTORY_SIZE:]
+	}
+	return h
+}
+
+// add a line, empty lines and repeats of the last one are skipped.
+// The file is rewritten when it's too long, otherwise the line is appended to it
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" || len(h.lines) > 0 && h.lines[len(h.lines)-1] == line {
+		return
+	}
+	h.lines = append(h.lines, line)
+	if h.path == "" {
+		return
+	}
+	if len(h.lines) > HISTORY_SIZE {
+		h.lines = h.lines[len(h.lines)-HISTORY_SIZE:]
+		h.save()
+		return
+	}
+	// the history is a convenience, failing to save it isn't worth stopping the REPL
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	file.WriteString(escapeHistory(line) + "\n")
+}
+
+func (h *history) save() {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	var out strings.Builder
+	for _, line := range h.lines {
+		out.WriteString(escapeHistory(line) + "\n")
+	}
+	os.WriteFile(h.path, []byte(out.String()), 0o600)
+}
+
+func escapeHistory(line string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(line)
+}
+
+func unescapeHistory(line string) string {
+	var out strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) {
+			i++
+			if line[i] == 'n' {
+				out.WriteByte('\n')
+				continue
+			}
+		}
+		out.WriteByte(line[i])
+	}
+	return out.String()
+}
+
+// the index of the newest line before `before` that contains query, -1 if there's none
+func (h *history) search(query string, before int) int {
+	for i := before - 1; i >= 0; i-- {
+		if strings.Contains(h.lines[i], query) {
+			return i
+		}
+	}
+	return -1
+}
@@ -9,11 +9,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"strings"
 	"trash/eval"
+	"trash/highlight"
 	"trash/lexer"
 	"trash/object"
 	"trash/parser"
+	"trash/token"
 )
 
 const TRASH_ICON = `
@@ -28,33 +31,109 @@ const TRASH_ICON = `
 
 const PROMPT = ">> "
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnv()
+// the prompt of the next lines of an input that isn't complete yet : an open brace, bracket, paren or string
+const CONTINUATION_PROMPT = ".. "
+
+func Start(in io.Reader, out io.Writer, strict bool) {
+	s := newSession(out, strict)
+	if file, ok := out.(*os.File); ok {
+		if width := terminalWidth(file.Fd()); width > 0 {
+			s.width = width
+		}
+	}
+	reader := newLineReader(in, out, s)
 
-	fmt.Print(TRASH_ICON)
+	fmt.Fprint(out, TRASH_ICON)
 	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		input, ok := readInput(reader)
+		if !ok {
 			return
 		}
-		line := scanner.Text()
-		l := lexer.New(line)
-
-		parser := parser.New(l)
-		prog := parser.Parse()
-		if len(parser.Errors()) != 0 {
-			logErrors(out, parser.Errors())
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		if strings.HasPrefix(input, ":") {
+			s.command(input)
 			continue
 		}
+		s.run(input)
+	}
+}
 
-		evaluated := eval.Eval(prog, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+// the line editor when the input is a terminal, with the history of the previous sessions, plain lines otherwise
+func newLineReader(in io.Reader, out io.Writer, s *session) lineReader {
+	if file, ok := in.(*os.File); ok && isTerminal(file.Fd()) {
+		raw := func() (func(), error) { return makeRaw(file.Fd()) }
+		if restore, err := raw(); err == nil {
+			restore()
+			e := &editor{
+				in:       bufio.NewReader(in),
+				out:      out,
+				history:  loadHistory(historyPath()),
+				complete: s.complete,
+				raw:      raw,
+			}
+			if highlight.ColorEnabled() {
+				e.colorize = highlight.ANSI
+			}
+			return e
+		}
+	}
+	return &plainReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// read lines until the input is complete, two empty lines in a row send it as is (to get out of a typo like an extra "{").
+// Ctrl-C drops the input. The complete input goes in the history as one entry, not line by line
+func readInput(reader lineReader) (string, bool) {
+	lines := []string{}
+	prompt := PROMPT
+	for {
+		line, err := reader.readLine(prompt)
+		if err == errInterrupted {
+			return "", true
+		}
+		if err != nil {
+			// end of input, whatever was read is still evaluated
+			input := strings.Join(lines, "\n")
+			reader.addHistory(input)
+			return input, len(lines) != 0
+		}
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+		if isComplete(input) || endsWithEmptyLines(lines) {
+			reader.addHistory(strings.TrimRight(input, "\n"))
+			return input, true
+		}
+		prompt = CONTINUATION_PROMPT
+	}
+}
+
+// the input is complete when its brackets are balanced and it has no unterminated string,
+// it's the lexer that decides, so a brace in a string doesn't count.
+// Too many closing brackets is complete too, the parser reports it
+func isComplete(input string) bool {
+	l := lexer.New(input)
+	depth := 0
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LEFT_PAREN, token.LEFT_BRACE, token.LEFT_BRACKET:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACE, token.RIGHT_BRACKET:
+			depth--
+		case token.ILLEGAL:
+			// the unterminated strings (and ${) read until the end of the input
+			if strings.HasPrefix(tok.Literal, "unterminated") {
+				return false
+			}
 		}
 	}
+	return depth <= 0
+}
+
+func endsWithEmptyLines(lines []string) bool {
+	n := len(lines)
+	return n >= 3 && strings.TrimSpace(lines[n-1]) == "" && strings.TrimSpace(lines[n-2]) == ""
 }
 
 func logErrors(out io.Writer, errors []string) {
@@ -65,8 +144,9 @@ func logErrors(out io.Writer, errors []string) {
 	}
 }
 
-func StartWithFile(input io.Reader, output io.Writer) {
+func StartWithFile(input io.Reader, output io.Writer, strict bool) {
 	env := object.NewEnv()
+	env.SetStrict(strict)
 	content, err := ioutil.ReadAll(input)
 	if err != nil {
 		fmt.Fprintln(output, "Error reading the file:", err)
@@ -90,21 +170,3 @@ func StartWithFile(input io.Reader, output io.Writer) {
 		}
 	}
 }
-func IsStartOfBlock(line string) bool {
-	line = strings.TrimSpace(line)
-	if len(line) == 0 {
-		return false // Handle empty lines
-	}
-	lastChar := line[len(line)-1]
-	return lastChar == '{'
-}
-
-// IsEndOfBlock checks if a line marks the end of a code block.
-func IsEndOfBlock(line string) bool {
-	line = strings.TrimSpace(line)
-	if len(line) == 0 {
-		return false // Handle empty lines
-	}
-	lastChar := line[len(line)-1]
-	return lastChar == '}'
-}
@@ -0,0 +1,140 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsComplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		complete bool
+	}{
+		{"let x = 1", true},
+		{"", true},
+		{"let f = fn(x) {", false},
+		{"let f = fn(x) {\n  x * 2\n}", true},
+		{"[1, 2,", false},
+		{"f(1,\n2)", true},
+		{"let h = {\"a\": [1, (2", false},
+		{`"a { string"`, true},
+		{`"unterminated`, false},
+		{"`raw\nstring", false},
+		{"`raw\nstring`", true},
+		{`"${x`, false},
+		{"}", true},
+		{"if (x) { 1 } else {", false},
+	}
+	for _, tt := range tests {
+		if got := isComplete(tt.input); got != tt.complete {
+			t.Errorf("isComplete(%q): expected %t, got %t", tt.input, tt.complete, got)
+		}
+	}
+}
+
+func TestStartMultiline(t *testing.T) {
+	input := strings.Join([]string{
+		"let f = fn(x) {",
+		"  let y = x * 2",
+		"",
+		"  y + 1",
+		"}",
+		"f(20)",
+		"let s = `a",
+		"b`",
+		"s",
+		"let oops = {",
+		"",
+		"",
+		"1 + 1",
+	}, "\n")
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out, false)
+
+	got := strings.TrimPrefix(out.String(), TRASH_ICON)
+	expected := ">> .. .. .. .. " +
+		">> 41\n" +
+		">> .. " +
+		">> \"a\\nb\"\n" +
+		">> .. .. Oops errors :'( \n"
+	if !strings.HasPrefix(got, expected) {
+		t.Fatalf("wrong output. expected prefix=%q, got=%q", expected, got)
+	}
+	if !strings.HasSuffix(got, ">> 2\n>> ") {
+		t.Errorf("the input after an abandoned block wasn't evaluated, got=%q", got)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.tsh")
+	if err := os.WriteFile(file, []byte("let double = fn(x) { x * 2 }\nlet loaded = true"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":env", "The env is empty\n"},
+		{":load " + file, ""},
+		{"let xs = [1, 2]", ""},
+		{":env", "double: FUNCTION = <fn double(x)>\nloaded: BOOL = true\nxs: LIST = [1, 2]\n"},
+		{":type double(2)", "INT\n"},
+		{"[\"1\", 1, double]", "[\"1\", 1, <fn double(x)>]\n"},
+		{":type nope", "NameError: Identifier not found: nope\n"},
+		{":ast 1 + 2 * 3", "*ast.ExpressionStatement (1 + (2 * 3))\n"},
+		{":tokens let é = 1", "1:1\tLET\t\"let\"\n1:5\tIDENT\t\"é\"\n1:7\t=\t\"=\"\n1:9\tINT\t\"1\"\n"},
+		{":ast", "Usage: :ast <code>\n"},
+		{":load /does/not/exist.tsh", "Error reading the file: open /does/not/exist.tsh: no such file or directory\n"},
+		{":nope", "Unknown command :nope, :help lists them\n"},
+		{":reset", "The env is empty\n"},
+		{":env", "The env is empty\n"},
+	}
+	var out bytes.Buffer
+	s := newSession(&out, false)
+	for _, tt := range tests {
+		out.Reset()
+		if strings.HasPrefix(tt.input, ":") {
+			s.command(tt.input)
+		} else {
+			s.run(tt.input)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	out.Reset()
+	s.command(":time 1 + 1")
+	if !strings.HasPrefix(out.String(), "2\ntook ") {
+		t.Errorf(":time: wrong output %q", out.String())
+	}
+	out.Reset()
+	s.command(":help")
+	if strings.Count(out.String(), "\n") != len(commands) || !strings.Contains(out.String(), ":load <file>") {
+		t.Errorf(":help: wrong output %q", out.String())
+	}
+}
+
+func TestPrettyOutput(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(&out, false)
+	s.width = 22
+	s.run(`{"name": "trash", "tags": ["a", "b"], "big": [1000, 2000, 3000]}`)
+	expected := `{
+  "name": "trash",
+  "tags": ["a", "b"],
+  "big": [
+    1000,
+    2000,
+    3000
+  ]
+}
+`
+	if out.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, out.String())
+	}
+}
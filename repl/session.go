@@ -0,0 +1,227 @@
+/*
+A REPL session keeps the env between the inputs, and handles the meta-commands (starting with a colon) :-
+
+	:env           the bindings and their types
+	:ast <code>    the parsed program
+	:tokens <code> the tokens of the lexer
+	:type <expr>   the type of the value
+	:load <file>   evaluate a file in the current env
+	:time <code>   evaluate and print how long it took
+	:reset         start again with an empty env
+	:help          the list of commands
+*/
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"trash/ast"
+	"trash/eval"
+	"trash/lexer"
+	"trash/object"
+	"trash/parser"
+	"trash/token"
+)
+
+// the width the values are wrapped to when the output isn't a terminal
+const DEFAULT_WIDTH = 80
+
+type session struct {
+	env    *object.Env
+	out    io.Writer
+	strict bool
+	width  int // the lists and hashmaps wider than this are printed over multiple lines
+}
+
+func newSession(out io.Writer, strict bool) *session {
+	s := &session{out: out, strict: strict, width: DEFAULT_WIDTH}
+	s.reset()
+	return s
+}
+
+func (s *session) reset() {
+	s.env = object.NewEnv()
+	s.env.SetStrict(s.strict)
+}
+
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, arg string)
+}
+
+var commands []command
+
+// set in init() because :help reads the commands
+func init() {
+	commands = []command{
+		{"env", ":env", "list the bindings and their types", (*session).listEnv},
+		{"ast", ":ast <code>", "show the parsed program", (*session).showAst},
+		{"tokens", ":tokens <code>", "show the tokens of the lexer", (*session).showTokens},
+		{"type", ":type <expr>", "evaluate and show the type of the value", (*session).showType},
+		{"load", ":load <file>", "evaluate a file in the current env", (*session).load},
+		{"time", ":time <code>", "evaluate and show how long it took", (*session).time},
+		{"reset", ":reset", "forget all the bindings", func(s *session, _ string) {
+			s.reset()
+			fmt.Fprintln(s.out, "The env is empty")
+		}},
+		{"help", ":help", "show this help", (*session).help},
+	}
+}
+
+// run a ":name arg" line
+func (s *session) command(line string) {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(s, arg)
+			return
+		}
+	}
+	fmt.Fprintf(s.out, "Unknown command :%s, :help lists them\n", name)
+}
+
+// the keywords, builtins and bound names starting with prefix, for the tab completion
+func (s *session) complete(prefix string) []string {
+	seen := map[string]bool{}
+	matches := []string{}
+	for _, names := range [][]string{token.Keywords(), eval.BuiltinNames(), s.env.Names()} {
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) && !seen[name] {
+				seen[name] = true
+				matches = append(matches, name)
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// parse the input, logging the errors if there's any
+func (s *session) parse(input string) (*ast.Program, bool) {
+	p := parser.New(lexer.New(input))
+	prog := p.Parse()
+	if len(p.Errors()) != 0 {
+		logErrors(s.out, p.Errors())
+		return nil, false
+	}
+	return prog, true
+}
+
+// parse and evaluate the input in the session env, and print the result
+func (s *session) run(input string) {
+	if prog, ok := s.parse(input); ok {
+		s.print(eval.Eval(prog, s.env))
+	}
+}
+
+// values are printed in their repr form ("1" and 1 look different), errors as they are
+func (s *session) print(evaluated object.Object) {
+	if evaluated != nil {
+		io.WriteString(s.out, object.Pretty(evaluated, s.width))
+		io.WriteString(s.out, "\n")
+	}
+}
+
+// the argument of a command that needs one
+func (s *session) requireArg(usage, arg string) bool {
+	if arg == "" {
+		fmt.Fprintf(s.out, "Usage: %s\n", usage)
+		return false
+	}
+	return true
+}
+
+func (s *session) listEnv(_ string) {
+	names := s.env.Names()
+	if len(names) == 0 {
+		fmt.Fprintln(s.out, "The env is empty")
+		return
+	}
+	for _, name := range names {
+		val, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s: %s = %s\n", name, val.Type(), object.Repr(val))
+	}
+}
+
+func (s *session) showAst(arg string) {
+	if !s.requireArg(":ast <code>", arg) {
+		return
+	}
+	prog, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+	for _, stmt := range prog.Statements {
+		fmt.Fprintf(s.out, "%T %s\n", stmt, stmt.String())
+	}
+}
+
+func (s *session) showTokens(arg string) {
+	if !s.requireArg(":tokens <code>", arg) {
+		return
+	}
+	l := lexer.New(arg)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+	}
+}
+
+func (s *session) showType(arg string) {
+	if !s.requireArg(":type <expr>", arg) {
+		return
+	}
+	prog, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+	evaluated := eval.Eval(prog, s.env)
+	if evaluated == nil {
+		fmt.Fprintln(s.out, "no value")
+		return
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		s.print(err)
+		return
+	}
+	fmt.Fprintln(s.out, evaluated.Type())
+}
+
+func (s *session) load(arg string) {
+	if !s.requireArg(":load <file>", arg) {
+		return
+	}
+	content, err := os.ReadFile(arg)
+	if err != nil {
+		fmt.Fprintln(s.out, "Error reading the file:", err)
+		return
+	}
+	s.run(string(content))
+}
+
+func (s *session) time(arg string) {
+	if !s.requireArg(":time <code>", arg) {
+		return
+	}
+	prog, ok := s.parse(arg)
+	if !ok {
+		return
+	}
+	start := time.Now()
+	evaluated := eval.Eval(prog, s.env)
+	elapsed := time.Since(start)
+	s.print(evaluated)
+	fmt.Fprintf(s.out, "took %s\n", elapsed)
+}
+
+func (s *session) help(_ string) {
+	for _, cmd := range commands {
+		fmt.Fprintf(s.out, "%-16s %s\n", cmd.usage, cmd.help)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package repl
+
+import "errors"
+
+// no raw terminal here, the REPL reads plain lines
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func terminalWidth(fd uintptr) int {
+	return 0
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal not supported")
+}
@@ -3,29 +3,53 @@ This file contains all the token used for building our language, which are then
 */
 package token
 
+import "sort"
+
 // using a constant since our language is going to really limited and small, while it's better to use a hashmap
 const (
 	// identifiers: let IDENTIFER = 4;
 	IDENT  = "IDENT" // add, foobar, x, y
 	INT    = "INT"   // for numbers, only supports integers for now
 	STRING = "STRING"
+	INTERP = "INTERP" // "Hello ${name}", the literal is the raw body of the string
 
 	// operators: +, *, /, -
 	ASSIGN    = "="
 	PLUS      = "+"
 	COLON     = ":"
+	ARROW     = "=>"
 	NEG       = "-"
 	MUL       = "*"
 	DIV       = "/"
 	BANG      = "!"
 	LT        = "<"
 	GT        = ">"
+	MOD       = "%"
 	EQUAL     = "=="
 	NOT_EQUAL = "!="
 
+	// bitwise operators on integers: &, |, ^, ~, <<, >>
+	BIT_AND     = "&"
+	BIT_OR      = "|"
+	BIT_XOR     = "^"
+	BIT_NOT     = "~"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
+
+	// compound assignments and updates: x += 1, x++
+	PLUS_ASSIGN = "+="
+	NEG_ASSIGN  = "-="
+	MUL_ASSIGN  = "*="
+	DIV_ASSIGN  = "/="
+	MOD_ASSIGN  = "%="
+	INCREMENT   = "++"
+	DECREMENT   = "--"
+
 	// delimiters: (, ), {, }, ;, ,
 	SEMICOLON     = ";"
 	COMMA         = ","
+	DOT           = "."
+	ELLIPSIS      = "..." // ...rest
 	LEFT_PAREN    = "("
 	RIGHT_PAREN   = ")"
 	LEFT_BRACE    = "{"
@@ -34,13 +58,20 @@ const (
 	RIGHT_BRACKET = "]"
 
 	// keywords
-	FUNC   = "FUNCTION"
-	LET    = "LET"
-	IF     = "IF"
-	ELSE   = "ELSE"
-	RETURN = "RETURN"
-	TRUE   = "TRUE"
-	FALSE  = "FALSE"
+	FUNC    = "FUNCTION"
+	LET     = "LET"
+	IF      = "IF"
+	ELSE    = "ELSE"
+	RETURN  = "RETURN"
+	MATCH   = "MATCH"
+	TRY     = "TRY"
+	CATCH   = "CATCH"
+	FINALLY = "FINALLY"
+	THROW   = "THROW"
+	IS      = "IS"
+	IN      = "IN"
+	TRUE    = "TRUE"
+	FALSE   = "FALSE"
 
 	// special types
 	ILLEGAL = "ILLEGAL"
@@ -52,17 +83,37 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // the line the token starts at, starting at 1
+	Column  int // the column the token starts at, in characters (not bytes), starting at 1
+	Offset  int // the byte offset of the token in the input
 }
 
 // seperating user-defined identifiers from langauge keywords
 var keywords = map[string]TokenType{
-	"fn":     FUNC,
-	"let":    LET,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"true":   TRUE,
-	"false":  FALSE,
+	"fn":      FUNC,
+	"let":     LET,
+	"if":      IF,
+	"else":    ELSE,
+	"return":  RETURN,
+	"match":   MATCH,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
+	"is":      IS,
+	"in":      IN,
+	"true":    TRUE,
+	"false":   FALSE,
+}
+
+// the keywords of the language, sorted
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 func LookIdentifier(ident string) TokenType {